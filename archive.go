@@ -0,0 +1,183 @@
+package sri
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// ArchiveSeparator separates the path of an archive from the path of a file inside it, e.g
+// 'bundle.tgz!package/app.js'.
+const ArchiveSeparator = "!"
+
+// archiveFormats maps the extensions of supported archives to their format.
+var archiveFormats = []struct{ ext, format string }{
+	{".zip", "zip"},
+	{".tar", "tar"},
+	{".tgz", "tgz"},
+	{".tar.gz", "tgz"},
+}
+
+// archiveFormat returns the format of an archive by its extension, or "" if the name isn't an archive.
+func archiveFormat(name string) string {
+	lower := strings.ToLower(name)
+	for _, a := range archiveFormats {
+		if strings.HasSuffix(lower, a.ext) {
+			return a.format
+		}
+	}
+
+	return ""
+}
+
+// splitArchiveTarget splits a target of the form 'archive!member' into the archive and member, if the archive exists.
+func splitArchiveTarget(target string) (archive, member string, ok bool) {
+	idx := strings.Index(target, ArchiveSeparator)
+	if idx <= 0 {
+		return "", "", false
+	}
+
+	archive, member = target[:idx], target[idx+1:]
+	if archiveFormat(archive) == "" || member == "" {
+		return "", "", false
+	}
+
+	if fi, err := os.Stat(archive); err != nil || !fi.Mode().IsRegular() {
+		return "", "", false
+	}
+
+	return archive, member, true
+}
+
+// Archive produces the integrities of every file inside a zip, tar or gzipped tar archive, without extracting it.
+// Members are identified as 'archive!member', with Include and Exclude matched against their path in the archive.
+func (g *Generator) Archive(target string) ([]Integrity, error) {
+	return g.archive(context.Background(), target, "")
+}
+
+// archive hashes the members of an archive, or only the named member if member isn't empty.
+func (g *Generator) archive(ctx context.Context, target, member string) ([]Integrity, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(target)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	fis := []Integrity{}
+	visit := func(name string, r io.Reader) error {
+		name = strings.TrimPrefix(path.Clean(strings.TrimPrefix(name, "./")), "/")
+		if member != "" && name != member || member == "" && !g.selected(name) {
+			return nil
+		}
+
+		mfis, err := g.Integrities(target+ArchiveSeparator+name, &contextReader{ctx: ctx, r: r})
+		if err != nil {
+			return fmt.Errorf("Unable to read %s from archive %s. %s", name, target, err)
+		}
+
+		for i := range mfis {
+			mfis[i].FileName = path.Base(target) + ArchiveSeparator + name
+		}
+
+		fis = append(fis, mfis...)
+		return nil
+	}
+
+	switch archiveFormat(target) {
+	case "zip":
+		err = walkZip(f, visit)
+	case "tgz":
+		var gz *gzip.Reader
+		if gz, err = gzip.NewReader(f); err == nil {
+			err = walkTar(gz, visit)
+			gz.Close()
+		}
+	default:
+		err = walkTar(f, visit)
+	}
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	} else if err != nil {
+		return nil, fmt.Errorf("Unable to read archive %s. %s", target, err)
+	}
+
+	if member != "" && len(fis) == 0 {
+		return nil, fmt.Errorf("No file %s in archive %s", member, target)
+	}
+
+	return fis, nil
+}
+
+// selected reports whether a file at the path rel would be hashed given the Include and Exclude patterns. Exclude
+// patterns are also matched against each of its parent directories, as they are when walking directories.
+func (g *Generator) selected(rel string) bool {
+	for dir := rel; dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if matchAny(g.Exclude, dir) {
+			return false
+		}
+	}
+
+	return len(g.Include) == 0 || matchAny(g.Include, rel)
+}
+
+func walkZip(f *os.File, visit func(name string, r io.Reader) error) error {
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	zr, err := zip.NewReader(f, fi.Size())
+	if err != nil {
+		return err
+	}
+
+	for _, zf := range zr.File {
+		if !zf.Mode().IsRegular() {
+			continue
+		}
+
+		rc, err := zf.Open()
+		if err != nil {
+			return err
+		}
+
+		err = visit(zf.Name, rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func walkTar(r io.Reader, visit func(name string, r io.Reader) error) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if !hdr.FileInfo().Mode().IsRegular() {
+			continue
+		}
+
+		if err := visit(hdr.Name, tr); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package sri
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var testArchiveFiles = map[string]string{
+	"package/test.js":         "test/test.js",
+	"package/vendor/test.css": "test/test.css",
+}
+
+func TestArchive(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "sri")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir. %q", err)
+	}
+	defer os.RemoveAll(dir)
+
+	zipPath := filepath.Join(dir, "release.zip")
+	writeTestArchive(t, zipPath, func(f *os.File) {
+		zw := zip.NewWriter(f)
+		for name, src := range testArchiveFiles {
+			w, err := zw.Create(name)
+			if err != nil {
+				t.Fatalf("Unable to create zip member. %q", err)
+			}
+			w.Write(readTestFile(t, src))
+		}
+		zw.Close()
+	})
+
+	tgzPath := filepath.Join(dir, "bundle.tgz")
+	writeTestArchive(t, tgzPath, func(f *os.File) {
+		gz := gzip.NewWriter(f)
+		tw := tar.NewWriter(gz)
+		tw.WriteHeader(&tar.Header{Name: "package/", Typeflag: tar.TypeDir, Mode: 0755})
+		for name, src := range testArchiveFiles {
+			b := readTestFile(t, src)
+			tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(b))})
+			tw.Write(b)
+		}
+		tw.Close()
+		gz.Close()
+	})
+
+	g := &Generator{Hash: SHA256, Exclude: []string{"vendor"}}
+	fis, err := g.Generate([]string{zipPath, tgzPath, tgzPath + "!package/vendor/test.css"})
+	if err != nil {
+		t.Fatalf("Unexpected error from Generate call. %q", err)
+	}
+
+	exp := map[string]string{
+		"bundle.tgz!package/test.js":         "sha256-jEUM4jWrIiMerWo9zYrx6XwQ5eI77uzuETBptBvPlRQ=",
+		"bundle.tgz!package/vendor/test.css": "sha256-ckxnbs3D4win9ik/Eh1/55cPi1yJ4xBVTU5npga+uw8=",
+		"release.zip!package/test.js":        "sha256-jEUM4jWrIiMerWo9zYrx6XwQ5eI77uzuETBptBvPlRQ=",
+	}
+
+	if len(fis) != len(exp) {
+		t.Fatalf("Expected %d integrities. Got %d (%+v)", len(exp), len(fis), fis)
+	}
+
+	for _, fi := range fis {
+		if exp[fi.FileName] != fi.Digest {
+			t.Fatalf("Expected %s to have digest %s. Got %s", fi.FileName, exp[fi.FileName], fi.Digest)
+		}
+	}
+
+	if _, err := g.Generate([]string{zipPath + "!package/not-real.js"}); err == nil {
+		t.Fatalf("Expected an error generating a file missing from an archive")
+	}
+}
+
+func writeTestArchive(t *testing.T, name string, write func(f *os.File)) {
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatalf("Unable to create test archive. %q", err)
+	}
+	defer f.Close()
+
+	write(f)
+}
+
+func readTestFile(t *testing.T, name string) []byte {
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatalf("Unable to read test file. %q", err)
+	}
+
+	return b
+}
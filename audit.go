@@ -0,0 +1,70 @@
+package sri
+
+import "strings"
+
+const (
+	AuditOK         = "ok"
+	AuditMissing    = "missing"
+	AuditMismatched = "mismatched"
+	AuditError      = "error"
+)
+
+// AuditFinding describes the integrity of a single script or stylesheet referenced by an HTML page.
+type AuditFinding struct {
+	Page               string `json:"page,omitempty"`
+	Line               int    `json:"line"`
+	Ref                string `json:"ref"`
+	CrossOrigin        bool   `json:"cross_origin"`
+	Integrity          string `json:"integrity,omitempty"`
+	Status             string `json:"status"`
+	MissingCrossOrigin bool   `json:"missing_crossorigin,omitempty"`
+	Error              string `json:"error,omitempty"`
+}
+
+// Audit reports on every script and stylesheet referenced by an HTML document; whether it has an integrity attribute,
+// whether that integrity matches the current content, and whether cross-origin references are missing a
+// crossorigin attribute. References are resolved as they are by Inject.
+func (g *Generator) Audit(doc []byte, dir, root string) []AuditFinding {
+	findings := []AuditFinding{}
+	all := *g
+	all.Hash = AllHashes
+
+	for _, tag := range scanTags(doc) {
+		ref, ok := tag.subresource()
+		if !ok {
+			continue
+		}
+
+		target, remote, ok := resolveRef(ref, dir, root)
+		if !ok {
+			continue
+		}
+
+		f := AuditFinding{Line: tag.Line, Ref: ref, CrossOrigin: remote}
+		if _, ok := tag.attr("crossorigin"); remote && !ok {
+			f.MissingCrossOrigin = true
+		}
+
+		integrity, ok := tag.attr("integrity")
+		if !ok || strings.TrimSpace(integrity.Value) == "" {
+			f.Status = AuditMissing
+			findings = append(findings, f)
+			continue
+		}
+		f.Integrity = integrity.Value
+
+		fis, err := all.resolved(target, remote)
+		if err != nil {
+			f.Status = AuditError
+			f.Error = err.Error()
+		} else if Match(integrity.Value, fis) {
+			f.Status = AuditOK
+		} else {
+			f.Status = AuditMismatched
+		}
+
+		findings = append(findings, f)
+	}
+
+	return findings
+}
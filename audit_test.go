@@ -0,0 +1,37 @@
+package sri
+
+import "testing"
+
+func TestAudit(t *testing.T) {
+	doc := `<html>
+<link rel="stylesheet" href="test/test.css">
+<script src="test/test.js" integrity="sha256-notthedigest sha384-zBTHeP/UZLYRhjvTi7r3Dx7MTCNf/ddGENI26AacmrgqzH8YOkA+EJ14MXpwD4wL"></script>
+<script src="test/test.min.js" integrity="sha384-notthedigest sha256-ODBnPrz8p2bs/l/ffyD4jUqpRkTvzlmFu8WDCuYNYms="></script>
+<script src="test/not-real.js" integrity="sha256-jEUM4jWrIiMerWo9zYrx6XwQ5eI77uzuETBptBvPlRQ="></script>
+<script src="https://cdn.com/app.js"></script>
+<script>console.log('inline scripts are not audited');</script>
+</html>`
+
+	g := &Generator{Hash: SHA256}
+	findings := g.Audit([]byte(doc), ".", ".")
+
+	exp := []AuditFinding{
+		{Line: 2, Ref: "test/test.css", Status: AuditMissing},
+		{Line: 3, Ref: "test/test.js", Status: AuditOK},
+		{Line: 4, Ref: "test/test.min.js", Status: AuditMismatched},
+		{Line: 5, Ref: "test/not-real.js", Status: AuditError},
+		{Line: 6, Ref: "https://cdn.com/app.js", Status: AuditMissing, CrossOrigin: true, MissingCrossOrigin: true},
+	}
+
+	if len(findings) != len(exp) {
+		t.Fatalf("Expected %d findings. Got %d (%+v)", len(exp), len(findings), findings)
+	}
+
+	for i, f := range findings {
+		e := exp[i]
+		if f.Line != e.Line || f.Ref != e.Ref || f.Status != e.Status || f.CrossOrigin != e.CrossOrigin ||
+			f.MissingCrossOrigin != e.MissingCrossOrigin {
+			t.Fatalf("Expected finding %+v. Got %+v", e, f)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/sHesl/sri"
+)
+
+// Exit codes of the audit command. They are combined when a page has more than one kind of problem, e.g a page
+// with both missing and mismatched integrities exits(6).
+const (
+	auditExitError              = 1
+	auditExitMissing            = 2
+	auditExitMismatched         = 4
+	auditExitMissingCrossOrigin = 8
+)
+
+// audit reports on the integrity of every script and stylesheet referenced by each HTML page (or directory of
+// pages) provided, as either a table or JSON.
+func audit(args []string) {
+	fs := flag.NewFlagSet("audit", flag.ExitOnError)
+	root := fs.String("root", ".", "Directory that root-relative references (e.g '/js/app.js') are resolved against")
+	format := fs.String("format", "table", "Output format. Valid: table, json")
+	httpOpts := addHTTPFlags(fs)
+	targets := parseArgs(fs, args)
+
+	if *format != "table" && *format != "json" {
+		log.Fatalf("[sri] Invalid value for flag '-format'. Expected one of 'table' or 'json'")
+	}
+
+	pages, err := htmlFiles(targets)
+	if err != nil {
+		log.Fatalf("[sri] Unable to find HTML pages. %q", err)
+	}
+
+	g := &sri.Generator{Hash: sri.AllHashes}
+	if err := httpOpts.configure(g); err != nil {
+		log.Fatalf("[sri] Invalid HTTP configuration. %q", err)
+	}
+	findings := []sri.AuditFinding{}
+	for _, page := range pages {
+		doc, err := ioutil.ReadFile(page)
+		if err != nil {
+			log.Fatalf("[sri] Unable to read %s. %q", page, err)
+		}
+
+		for _, f := range g.Audit(doc, filepath.Dir(page), *root) {
+			f.Page = page
+			findings = append(findings, f)
+		}
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+
+		if err := enc.Encode(findings); err != nil {
+			log.Fatalf("An error occured writing audit to stdout")
+		}
+	} else {
+		printAuditTable(findings)
+	}
+
+	os.Exit(auditExitCode(findings))
+}
+
+func auditExitCode(findings []sri.AuditFinding) int {
+	code := 0
+	for _, f := range findings {
+		switch f.Status {
+		case sri.AuditMissing:
+			code |= auditExitMissing
+		case sri.AuditMismatched:
+			code |= auditExitMismatched
+		case sri.AuditError:
+			code |= auditExitError
+		}
+
+		if f.MissingCrossOrigin {
+			code |= auditExitMissingCrossOrigin
+		}
+	}
+
+	return code
+}
+
+func printAuditTable(findings []sri.AuditFinding) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PAGE\tLINE\tSTATUS\tCROSSORIGIN\tREF")
+
+	for _, f := range findings {
+		crossOrigin := "-"
+		if f.MissingCrossOrigin {
+			crossOrigin = "missing"
+		} else if f.CrossOrigin {
+			crossOrigin = "ok"
+		}
+
+		status := f.Status
+		if f.Error != "" {
+			status = fmt.Sprintf("%s (%s)", f.Status, f.Error)
+		}
+
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\n", f.Page, f.Line, status, crossOrigin, f.Ref)
+	}
+
+	w.Flush()
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/sHesl/sri"
+)
+
+func TestAuditExitCode(t *testing.T) {
+	type testCase struct {
+		findings []sri.AuditFinding
+		exp      int
+	}
+
+	testCases := []testCase{
+		{
+			findings: []sri.AuditFinding{{Status: sri.AuditOK}},
+			exp:      0,
+		},
+		{
+			findings: []sri.AuditFinding{{Status: sri.AuditOK}, {Status: sri.AuditMissing}},
+			exp:      auditExitMissing,
+		},
+		{
+			findings: []sri.AuditFinding{{Status: sri.AuditMismatched}},
+			exp:      auditExitMismatched,
+		},
+		{
+			findings: []sri.AuditFinding{{Status: sri.AuditMissing}, {Status: sri.AuditMismatched, MissingCrossOrigin: true}},
+			exp:      auditExitMissing | auditExitMismatched | auditExitMissingCrossOrigin,
+		},
+	}
+
+	for _, tc := range testCases {
+		if code := auditExitCode(tc.findings); code != tc.exp {
+			t.Fatalf("Expected exit code %d. Got %d", tc.exp, code)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/sHesl/sri"
+)
+
+// check decides whether a browser would accept the target against the given integrity attribute value, exiting(0)
+// if it would and exiting(1) if the resource would be blocked.
+func check(args []string) {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	httpOpts := addHTTPFlags(fs)
+	targets := parseArgs(fs, args)
+
+	if len(targets) != 2 || targets[0] == "" {
+		log.Fatalf("[sri] Expected a target and an integrity attribute value. e.g sri check app.js \"sha384-...\"")
+	}
+
+	g := &sri.Generator{Hash: sri.AllHashes}
+	if err := httpOpts.configure(g); err != nil {
+		log.Fatalf("[sri] Invalid HTTP configuration. %q", err)
+	}
+	fis, err := g.Generate([]string{targets[0]})
+	if err != nil {
+		log.Fatalf("[sri] An error occured generating integrities for %s. %q", targets[0], err)
+	}
+
+	warnCORS(fis)
+
+	ms := sri.ParseMetadata(targets[1])
+	if len(ms) == 0 {
+		fmt.Println("No valid integrity metadata, the resource will be loaded without an integrity check")
+		os.Exit(0)
+	}
+
+	for _, m := range sri.StrongestMetadata(ms) {
+		fmt.Printf("checking %s\n", m.Digest())
+	}
+
+	if !sri.Match(targets[1], fis) {
+		for _, fi := range fis {
+			fmt.Printf("%s - %s\n", targets[0], fi.Digest)
+		}
+		fmt.Println("Integrity did not match, the resource would be blocked")
+		os.Exit(1)
+	}
+
+	fmt.Println("Integrity matches")
+	os.Exit(0)
+}
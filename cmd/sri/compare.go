@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/sHesl/sri"
+)
+
+// runCompare compares the digests of every target, printing them along with whether they match as either text or
+// JSON, and exits(0) if they all match or exits(1) if any differ.
+func runCompare(targets []string) {
+	if err := validateCompare(targets); err != nil {
+		log.Fatalf("[sri] Unable to perform comparison. %q", err)
+	}
+
+	if *compareFormat != "text" && *compareFormat != "json" {
+		log.Fatalf("[sri] Invalid value for flag '-format'. Expected one of 'text' or 'json'")
+	}
+
+	g := &sri.Generator{Hash: *hashAlgo, Type: *targetType, StdinName: *name}
+	if err := httpOpts.configure(g); err != nil {
+		log.Fatalf("[sri] Invalid HTTP configuration. %q", err)
+	}
+
+	targets, expected, err := splitExpected(targets)
+	if err != nil {
+		log.Fatalf("[sri] Unable to perform comparison. %q", err)
+	}
+
+	var comparison *sri.Comparison
+	if expected != "" {
+		comparison, err = g.CompareExpected(targets, expected)
+	} else {
+		comparison, err = g.CompareAll(targets)
+	}
+	if err != nil {
+		log.Fatalf("[sri] An error occured during comparison. %s", formatErr(err))
+	}
+
+	if *compareFormat == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+
+		if err := enc.Encode(comparison); err != nil {
+			log.Fatalf("An error occured writing comparison to stdout")
+		}
+	} else {
+		printComparison(targets, comparison)
+	}
+
+	if !comparison.Match {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func printComparison(targets []string, comparison *sri.Comparison) {
+	for _, target := range targets {
+		fmt.Printf("%s - %s\n", target, comparison.Digests[target])
+	}
+
+	if comparison.Expected != "" {
+		fmt.Printf("expected - %s\n", comparison.Expected)
+		if comparison.Match {
+			fmt.Println("Integrity matches")
+		} else {
+			fmt.Println("Integrity did not match, the resource would be blocked")
+		}
+		return
+	}
+
+	if comparison.Match {
+		fmt.Println("Digests match")
+		return
+	}
+
+	fmt.Println("Digests did not match")
+	if len(targets) > 2 {
+		for i, group := range comparison.Groups {
+			fmt.Printf("Group %d: %s\n", i+1, strings.Join(group.Targets, ", "))
+		}
+	}
+}
+
+// splitExpected separates an expected integrity attribute value (e.g 'sha384-...') from the targets of a comparison.
+// Arguments are only treated as integrity values if they have valid metadata and aren't an existing file.
+func splitExpected(args []string) ([]string, string, error) {
+	targets, expected := []string{}, ""
+	for _, arg := range args {
+		if _, err := os.Stat(arg); err == nil || len(sri.ParseMetadata(arg)) == 0 {
+			targets = append(targets, arg)
+			continue
+		}
+
+		if expected != "" {
+			return nil, "", fmt.Errorf("Received more than one expected integrity for comparison")
+		}
+		expected = arg
+	}
+
+	return targets, expected, nil
+}
+
+func validateCompare(args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("Expected at least two targets to be specified for comparison")
+	}
+
+	seen := make(map[string]bool)
+	for _, arg := range args {
+		if arg == "" {
+			return fmt.Errorf("Received an empty target for comparison")
+		}
+
+		if seen[arg] {
+			return fmt.Errorf("Received two indentical inputs for comparison")
+		}
+		seen[arg] = true
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateCompare(t *testing.T) {
+	type testCase struct {
+		inputs []string
+		errMsg string
+	}
+
+	testCases := []testCase{
+		{
+			inputs: []string{"only one input"},
+			errMsg: "Expected at least two targets to be specified for comparison",
+		},
+		{
+			inputs: []string{"two inputs", "two different inputs"},
+			errMsg: "",
+		},
+		{
+			inputs: []string{"two inputs", ""},
+			errMsg: "Received an empty target for comparison",
+		},
+		{
+			inputs: []string{"two identical inputs", "two identical inputs"},
+			errMsg: "Received two indentical inputs for comparison",
+		},
+		{
+			inputs: []string{"", "two inputs"},
+			errMsg: "Received an empty target for comparison",
+		},
+		{
+			inputs: []string{"three inputs", "three different inputs", "three more different inputs"},
+			errMsg: "",
+		},
+		{
+			inputs: []string{"three inputs", "three different inputs", "three inputs"},
+			errMsg: "Received two indentical inputs for comparison",
+		},
+	}
+
+	for _, tc := range testCases {
+		err := validateCompare(tc.inputs)
+
+		if err == nil && tc.errMsg != "" {
+			t.Fatalf("Expected an error from validateCompare call")
+		}
+
+		if tc.errMsg == "" && err != nil {
+			t.Fatalf("Unexpected an error from validateCompare call. %q", err)
+		}
+
+		if err != nil && err.Error() != tc.errMsg {
+			t.Fatalf("Expected error message of %s. Got %q", tc.errMsg, err)
+		}
+	}
+}
+
+func TestSplitExpected(t *testing.T) {
+	targets, expected, err := splitExpected([]string{"../../test/test.js", "sha384-abc", "https://cdn.com/app.js"})
+	if err != nil {
+		t.Fatalf("Unexpected error from splitExpected call. %q", err)
+	}
+
+	if exp := []string{"../../test/test.js", "https://cdn.com/app.js"}; !reflect.DeepEqual(targets, exp) {
+		t.Fatalf("Expected targets %q. Got %q", exp, targets)
+	}
+
+	if expected != "sha384-abc" {
+		t.Fatalf("Expected integrity sha384-abc. Got %q", expected)
+	}
+
+	if _, _, err := splitExpected([]string{"app.js", "sha384-abc", "sha256-def"}); err == nil {
+		t.Fatalf("Expected an error splitting more than one expected integrity")
+	}
+}
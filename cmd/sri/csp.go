@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/sHesl/sri"
+)
+
+// cspOutput is the JSON output of the csp command.
+type cspOutput struct {
+	Policy string            `json:"policy"`
+	CSP    *sri.CSP          `json:"directives"`
+	Inline []sri.InlineBlock `json:"inline"`
+}
+
+// csp prints the Content-Security-Policy hash sources allowing the inline scripts and styles of each HTML page (or
+// directory of pages) provided, as either a header fragment or JSON.
+func csp(args []string) {
+	fs := flag.NewFlagSet("csp", flag.ExitOnError)
+	hashAlgo := fs.String("hash", "sha256", "Hashing algorithm, or a comma separated list of algorithms")
+	root := fs.String("root", ".", "Directory that root-relative references (e.g '/js/app.js') are resolved against")
+	format := fs.String("format", "header", "Output format. Valid: header, json")
+	strictDynamic := fs.Bool("strict-dynamic", false, "Add 'strict-dynamic' to script-src, along with the hashes of external scripts")
+	external := fs.Bool("external", false, "Add the hashes of external scripts to script-src")
+	requireSRIFor := fs.Bool("require-sri-for", false, "Add a require-sri-for directive covering scripts and styles")
+	httpOpts := addHTTPFlags(fs)
+	targets := parseArgs(fs, args)
+
+	if *format != "header" && *format != "json" {
+		log.Fatalf("[sri] Invalid value for flag '-format'. Expected one of 'header' or 'json'")
+	}
+
+	if err := sri.ValidateHash(*hashAlgo); err != nil {
+		log.Fatalf("[sri] Invalid value for flag '-hash'. %q", err)
+	}
+
+	pages, err := htmlFiles(targets)
+	if err != nil {
+		log.Fatalf("[sri] Unable to find HTML pages. %q", err)
+	}
+
+	g := &sri.Generator{Hash: *hashAlgo}
+	if err := httpOpts.configure(g); err != nil {
+		log.Fatalf("[sri] Invalid HTTP configuration. %q", err)
+	}
+
+	out := cspOutput{
+		CSP:    &sri.CSP{StrictDynamic: *strictDynamic, RequireSRIFor: *requireSRIFor},
+		Inline: []sri.InlineBlock{},
+	}
+
+	for _, page := range pages {
+		doc, err := ioutil.ReadFile(page)
+		if err != nil {
+			log.Fatalf("[sri] Unable to read %s. %q", page, err)
+		}
+
+		blocks, err := g.InlineBlocks(doc)
+		if err != nil {
+			log.Fatalf("[sri] Unable to hash inline blocks of %s. %q", page, err)
+		}
+
+		for _, b := range blocks {
+			b.Page = page
+			out.Inline = append(out.Inline, b)
+		}
+		out.CSP.Add(blocks...)
+
+		if *strictDynamic || *external {
+			sources, err := g.ExternalScriptSources(doc, filepath.Dir(page), *root)
+			if err != nil {
+				log.Fatalf("[sri] Unable to hash external scripts of %s. %s", page, formatErr(err))
+			}
+			out.CSP.AddSources(sri.CSPScriptSrc, sources...)
+		}
+	}
+	out.Policy = out.CSP.String()
+
+	if *format == "header" {
+		fmt.Println(out.Policy)
+		return
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(out); err != nil {
+		log.Fatalf("An error occured writing policy to stdout")
+	}
+}
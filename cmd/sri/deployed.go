@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"text/tabwriter"
+
+	"github.com/sHesl/sri"
+)
+
+// deployed checks every file of a local build directory against the copy deployed at its public URL, as either a
+// table or JSON, exiting(1) if any deployed file is missing or differs.
+func deployed(args []string) {
+	fs := flag.NewFlagSet("deployed", flag.ExitOnError)
+	hashAlgo := fs.String("hash", "sha256", "Hashing algorithm, or a comma separated list of algorithms")
+	format := fs.String("format", "table", "Output format. Valid: table, json")
+	include := fs.String("include", "", "Comma separated glob patterns of files to check")
+	exclude := fs.String("exclude", "", "Comma separated glob patterns of files to skip")
+	symlinks := fs.String("symlinks", sri.SymlinksSkip, "Policy for symlinks within directories. Valid: skip, follow, error")
+	concurrency := fs.Int("concurrency", runtime.NumCPU(), "Maximum number of files to check at once")
+	httpOpts := addHTTPFlags(fs)
+	urlOpts := addURLFlags(fs)
+	targets := parseArgs(fs, args)
+
+	if *format != "table" && *format != "json" {
+		log.Fatalf("[sri] Invalid value for flag '-format'. Expected one of 'table' or 'json'")
+	}
+
+	if err := sri.ValidateHash(*hashAlgo); err != nil {
+		log.Fatalf("[sri] Invalid value for flag '-hash'. %q", err)
+	}
+
+	if len(targets) != 1 || targets[0] == "" {
+		log.Fatalf("[sri] Expected a single directory to check")
+	}
+
+	if *urlOpts.baseURL == "" && len(urlOpts.rewrites) == 0 {
+		log.Fatalf("[sri] Expected '-base-url' or '-rewrite' to map files to their deployed URLs")
+	}
+
+	if len(urlOpts.envs) > 0 {
+		log.Fatalf("[sri] Unable to check more than one environment at once. Use '-base-url' in place of '-env'")
+	}
+
+	g := &sri.Generator{
+		Hash:        *hashAlgo,
+		Include:     splitList(*include),
+		Exclude:     splitList(*exclude),
+		Symlinks:    *symlinks,
+		Concurrency: *concurrency,
+	}
+	if err := httpOpts.configure(g); err != nil {
+		log.Fatalf("[sri] Invalid HTTP configuration. %q", err)
+	}
+	urlOpts.configure(g)
+
+	files, err := g.Deployed(interruptContext(), targets[0])
+	if err != nil {
+		log.Fatalf("[sri] Unable to check deployed files. %q", err)
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+
+		if err := enc.Encode(files); err != nil {
+			log.Fatalf("An error occured writing deployed files to stdout")
+		}
+	} else {
+		printDeployedTable(files)
+	}
+
+	for _, f := range files {
+		if f.Status != sri.DeployedMatch {
+			os.Exit(1)
+		}
+	}
+}
+
+func printDeployedTable(files []sri.DeployedFile) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STATUS\tFILE\tURL")
+
+	counts := make(map[string]int)
+	for _, f := range files {
+		counts[f.Status]++
+
+		status := f.Status
+		if f.Status == sri.DeployedError {
+			status = fmt.Sprintf("%s (%s)", f.Status, f.Error)
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\n", status, f.File, f.URL)
+	}
+
+	w.Flush()
+
+	fmt.Printf("\n%d matched, %d mismatched, %d not found, %d errors\n", counts[sri.DeployedMatch],
+		counts[sri.DeployedMismatch], counts[sri.DeployedNotFound], counts[sri.DeployedError])
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/sHesl/sri"
+)
+
+// fingerprint copies (or renames) each file of a directory to a name including a short digest of its content,
+// rewriting references to them, and writes the mapping of original to fingerprinted names as JSON.
+func fingerprint(args []string) {
+	fs := flag.NewFlagSet("fingerprint", flag.ExitOnError)
+	hashAlgo := fs.String("hash", "sha256", "Hashing algorithm, or a comma separated list of algorithms")
+	length := fs.Int("length", sri.DefaultFingerprintLength, "Number of hex characters of the digest to include in names")
+	rename := fs.Bool("rename", false, "Remove the original files, rather than keeping them alongside the fingerprinted copies")
+	dryRun := fs.Bool("dry-run", false, "Print the mapping without writing any files")
+	include := fs.String("include", "", "Comma separated glob patterns of files to fingerprint")
+	exclude := fs.String("exclude", "", "Comma separated glob patterns of files to skip")
+	symlinks := fs.String("symlinks", sri.SymlinksSkip, "Policy for symlinks within directories. Valid: skip, follow, error")
+	outPath := fs.String("out", "", "Name of the mapping file")
+	targets := parseArgs(fs, args)
+
+	if len(targets) != 1 || targets[0] == "" {
+		log.Fatalf("[sri] Expected a single directory to fingerprint")
+	}
+
+	if err := sri.ValidateHash(*hashAlgo); err != nil {
+		log.Fatalf("[sri] Invalid value for flag '-hash'. %q", err)
+	}
+
+	g := &sri.Generator{
+		Hash:     *hashAlgo,
+		Include:  splitList(*include),
+		Exclude:  splitList(*exclude),
+		Symlinks: *symlinks,
+	}
+
+	opts := sri.FingerprintOptions{Length: *length, Rename: *rename, DryRun: *dryRun}
+	m, rewritten, err := g.Fingerprint(targets[0], opts)
+	if err != nil {
+		log.Fatalf("[sri] Unable to fingerprint %s. %q", targets[0], err)
+	}
+
+	for _, page := range rewritten {
+		fmt.Fprintf(os.Stderr, "%s - references rewritten\n", page)
+	}
+
+	w := io.Writer(os.Stdout)
+	if *outPath != "" && !*dryRun {
+		f, err := os.Create(*outPath)
+		if err != nil {
+			log.Fatalf("[sri] Unable to create file at location: %s. %q", *outPath, err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(m); err != nil {
+		log.Fatalf("[sri] An error occured writing fingerprint mapping. %q", err)
+	}
+}
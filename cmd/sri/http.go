@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sHesl/sri"
+)
+
+const (
+	bearerTokenEnv = "SRI_BEARER_TOKEN"
+	basicAuthEnv   = "SRI_BASIC_AUTH"
+)
+
+// httpFlags configures how remote targets are downloaded. They are shared by every command that downloads.
+type httpFlags struct {
+	timeout      *time.Duration
+	headers      headerFlag
+	authHosts    *string
+	userAgent    *string
+	retries      *int
+	retryBackoff *time.Duration
+	maxRedirects *int
+	expectType   *string
+	minSize      *int64
+	origin       *string
+	crossOrigin  *string
+	cors         *string
+}
+
+func addHTTPFlags(fs *flag.FlagSet) *httpFlags {
+	h := &httpFlags{headers: headerFlag{}}
+	h.timeout = fs.Duration("timeout", 30*time.Second, "Timeout for downloading each remote target")
+	fs.Var(h.headers, "header", "Header to send when downloading, as 'Name: value'. May be repeated")
+	h.authHosts = fs.String("auth-host", "", "Comma separated hosts credentials and any Authorization header are sent to")
+	h.userAgent = fs.String("user-agent", "sri", "User-Agent to send when downloading")
+	h.retries = fs.Int("retries", 2, "Number of times to retry a download after a timeout or 5xx response")
+	h.retryBackoff = fs.Duration("retry-backoff", 500*time.Millisecond, "Wait before the first retry, doubling for each retry after")
+	h.maxRedirects = fs.Int("max-redirects", 10, "Maximum number of redirects to follow")
+	h.expectType = fs.String("expect-type", "", "Content-Type expected of downloads. Valid: auto, js, css or a MIME type")
+	h.minSize = fs.Int64("min-size", 0, "Minimum number of bytes expected of each download")
+	h.origin = fs.String("origin", "", "Origin to send with downloads, checking the CORS headers of each response")
+	h.crossOrigin = fs.String("crossorigin", "", "crossorigin mode of tags, and to check CORS headers against. Valid: anonymous, use-credentials, none")
+	h.cors = fs.String("cors", "warn", "Whether CORS problems warn or fail. Valid: warn, fail")
+
+	return h
+}
+
+// configure applies the flags to g. Credentials are read from the SRI_BEARER_TOKEN environment variable, or from
+// SRI_BASIC_AUTH as 'user:password', so they don't end up in shell history. They are only sent to the hosts named by
+// '-auth-host', as pages may reference third-party URLs.
+func (h *httpFlags) configure(g *sri.Generator) error {
+	g.Client = sri.NewClient(*h.timeout, *h.maxRedirects)
+	g.Retries = *h.retries
+	g.RetryBackoff = *h.retryBackoff
+	g.ExpectContentType = *h.expectType
+	g.MinSize = *h.minSize
+	g.Origin = strings.TrimSuffix(*h.origin, "/")
+	g.CrossOrigin = *h.crossOrigin
+	g.StrictCORS = *h.cors == "fail"
+
+	switch g.CrossOrigin {
+	case "", sri.CrossOriginAnonymous, sri.CrossOriginUseCredentials, sri.CrossOriginNone:
+	default:
+		return fmt.Errorf("Invalid crossorigin mode '%s'. Expected one of 'anonymous', 'use-credentials' or 'none'", g.CrossOrigin)
+	}
+
+	if *h.cors != "warn" && *h.cors != "fail" {
+		return fmt.Errorf("Invalid value for '-cors' '%s'. Expected one of 'warn' or 'fail'", *h.cors)
+	}
+
+	g.Headers = http.Header{}
+	for name, values := range h.headers {
+		g.Headers[name] = values
+	}
+
+	if *h.userAgent != "" {
+		g.Headers.Set("User-Agent", *h.userAgent)
+	}
+
+	token, basic := os.Getenv(bearerTokenEnv), os.Getenv(basicAuthEnv)
+	switch {
+	case token != "" && basic != "":
+		return fmt.Errorf("Only one of %s and %s may be set", bearerTokenEnv, basicAuthEnv)
+	case token != "":
+		g.Headers.Set("Authorization", "Bearer "+token)
+	case basic != "":
+		if !strings.Contains(basic, ":") {
+			return fmt.Errorf("Expected %s to be of the form 'user:password'", basicAuthEnv)
+		}
+		g.Headers.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(basic)))
+	}
+
+	g.AuthHosts = splitList(*h.authHosts)
+	if g.Headers.Get("Authorization") != "" && len(g.AuthHosts) == 0 {
+		return fmt.Errorf("Expected '-auth-host' naming the hosts to send credentials to")
+	}
+
+	return nil
+}
+
+// headerFlag collects repeated '-header' flags.
+type headerFlag http.Header
+
+func (h headerFlag) String() string {
+	return fmt.Sprint(http.Header(h))
+}
+
+func (h headerFlag) Set(value string) error {
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+		return fmt.Errorf("Expected header of the form 'Name: value'. Got '%s'", value)
+	}
+
+	http.Header(h).Add(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+	return nil
+}
+
+// warnCORS logs a warning for every remote integrity whose response failed the CORS check.
+func warnCORS(fis []sri.Integrity) {
+	warned := make(map[string]bool)
+	for _, fi := range fis {
+		if fi.Response == nil || fi.Response.CORSError == "" || warned[fi.Source] {
+			continue
+		}
+
+		warned[fi.Source] = true
+		log.Printf("[sri] Warning: %s can't be used cross-origin. %s", fi.Source, fi.Response.CORSError)
+	}
+}
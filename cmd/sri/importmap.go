@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/sHesl/sri"
+)
+
+// importmap emits an import map with an integrity section, either covering every module in a directory, or
+// updating the integrity section of an existing import map JSON file.
+func importmap(args []string) {
+	fs := flag.NewFlagSet("importmap", flag.ExitOnError)
+	hashAlgo := fs.String("hash", "sha256", "Hashing algorithm, or a comma separated list of algorithms")
+	prefix := fs.String("prefix", "/", "URL prefix of the modules in a directory target, e.g '/modules/'")
+	root := fs.String("root", ".", "Directory that root-relative addresses (e.g '/js/app.js') are resolved against")
+	include := fs.String("include", "", "Comma separated glob patterns of modules to include from a directory target")
+	exclude := fs.String("exclude", "", "Comma separated glob patterns of modules to skip within a directory target")
+	outPath := fs.String("out", "", "Name of output file")
+	asHTML := fs.Bool("html", false, "Wrap the import map in a <script type=\"importmap\"> tag")
+	httpOpts := addHTTPFlags(fs)
+	targets := parseArgs(fs, args)
+
+	if len(targets) != 1 || targets[0] == "" {
+		log.Fatalf("[sri] Expected a single directory or import map JSON file")
+	}
+
+	if err := sri.ValidateHash(*hashAlgo); err != nil {
+		log.Fatalf("[sri] Invalid value for flag '-hash'. %q", err)
+	}
+
+	g := &sri.Generator{Hash: *hashAlgo, Include: splitList(*include), Exclude: splitList(*exclude)}
+	if err := httpOpts.configure(g); err != nil {
+		log.Fatalf("[sri] Invalid HTTP configuration. %q", err)
+	}
+
+	target := targets[0]
+	fi, err := os.Stat(target)
+	if err != nil {
+		log.Fatalf("[sri] Unable to read %s. %q", target, err)
+	}
+
+	var m *sri.ImportMap
+	if fi.IsDir() {
+		m, err = g.ImportMapFromDir(target, *prefix)
+	} else {
+		m, err = readImportMap(target)
+		if err == nil {
+			err = g.UpdateImportMap(m, filepath.Dir(target), *root)
+		}
+	}
+
+	if err != nil {
+		log.Fatalf("[sri] Unable to generate import map. %s", formatErr(err))
+	}
+
+	w := io.Writer(os.Stdout)
+	if *outPath != "" {
+		f, err := os.Create(*outPath)
+		if err != nil {
+			log.Fatalf("[sri] Unable to create file at location: %s. %q", *outPath, err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := writeImportMap(w, m, *asHTML); err != nil {
+		log.Fatalf("[sri] An error occured writing import map. %q", err)
+	}
+}
+
+func readImportMap(name string) (*sri.ImportMap, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	m := &sri.ImportMap{}
+	if err := json.NewDecoder(f).Decode(m); err != nil {
+		return nil, fmt.Errorf("Unable to parse import map %s. %s", name, err)
+	}
+
+	return m, nil
+}
+
+func writeImportMap(w io.Writer, m *sri.ImportMap, asHTML bool) error {
+	if asHTML {
+		fmt.Fprintln(w, `<script type="importmap">`)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(m); err != nil {
+		return err
+	}
+
+	if asHTML {
+		fmt.Fprintln(w, `</script>`)
+	}
+
+	return nil
+}
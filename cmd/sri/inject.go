@@ -0,0 +1,121 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sHesl/sri"
+)
+
+// inject adds or updates integrity attributes on the script and stylesheet tags of each HTML page (or directory of
+// pages) provided. In dry-run mode, the changes are printed as a diff and no files are written.
+func inject(args []string) {
+	fs := flag.NewFlagSet("inject", flag.ExitOnError)
+	hashAlgo := fs.String("hash", "sha256", "Hashing algorithm")
+	root := fs.String("root", ".", "Directory that root-relative references (e.g '/js/app.js') are resolved against")
+	dryRun := fs.Bool("dry-run", false, "Print a diff of the changes without writing them")
+	httpOpts := addHTTPFlags(fs)
+	targets := parseArgs(fs, args)
+
+	if err := sri.ValidateHash(*hashAlgo); err != nil {
+		log.Fatalf("[sri] Invalid value for flag '-hash'. %q", err)
+	}
+
+	pages, err := htmlFiles(targets)
+	if err != nil {
+		log.Fatalf("[sri] Unable to find HTML pages. %q", err)
+	}
+
+	g := &sri.Generator{Hash: *hashAlgo}
+	if err := httpOpts.configure(g); err != nil {
+		log.Fatalf("[sri] Invalid HTTP configuration. %q", err)
+	}
+	for _, page := range pages {
+		doc, err := ioutil.ReadFile(page)
+		if err != nil {
+			log.Fatalf("[sri] Unable to read %s. %q", page, err)
+		}
+
+		updated, injections, err := g.Inject(doc, filepath.Dir(page), *root)
+		if err != nil {
+			log.Fatalf("[sri] Unable to inject integrities into %s. %q", page, err)
+		}
+
+		if len(injections) == 0 {
+			continue
+		}
+
+		if *dryRun {
+			printInjectionDiff(page, injections)
+			continue
+		}
+
+		fi, err := os.Stat(page)
+		if err != nil {
+			log.Fatalf("[sri] Unable to stat %s. %q", page, err)
+		}
+
+		if err := ioutil.WriteFile(page, updated, fi.Mode()); err != nil {
+			log.Fatalf("[sri] Unable to write %s. %q", page, err)
+		}
+
+		fmt.Printf("%s - %d tag(s) updated\n", page, len(injections))
+	}
+
+	os.Exit(0)
+}
+
+func printInjectionDiff(page string, injections []sri.Injection) {
+	fmt.Printf("--- %s\n+++ %s\n", page, page)
+	for _, inj := range injections {
+		fmt.Printf("@@ line %d @@\n", inj.Line)
+		for _, l := range strings.Split(inj.Old, "\n") {
+			fmt.Printf("-%s\n", l)
+		}
+		for _, l := range strings.Split(inj.New, "\n") {
+			fmt.Printf("+%s\n", l)
+		}
+	}
+}
+
+// htmlFiles expands the given targets into a list of HTML pages, walking any directories for .html and .htm files.
+func htmlFiles(targets []string) ([]string, error) {
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("No HTML pages specified")
+	}
+
+	pages := []string{}
+	for _, target := range targets {
+		fi, err := os.Stat(target)
+		if err != nil {
+			return nil, err
+		}
+
+		if !fi.IsDir() {
+			pages = append(pages, target)
+			continue
+		}
+
+		err = filepath.Walk(target, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if ext := strings.ToLower(filepath.Ext(p)); info.Mode().IsRegular() && (ext == ".html" || ext == ".htm") {
+				pages = append(pages, p)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return pages, nil
+}
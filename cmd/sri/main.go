@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+
+	"github.com/sHesl/sri"
+)
+
+var (
+	compare       = flag.Bool("compare", false, "Run in comparison mode")
+	compareFormat = flag.String("format", "text", "Output format of comparisons. Valid: text, json")
+
+	hashAlgo = flag.String("hash", "sha256", "Hashing algorithm, or a comma separated list of algorithms")
+	combine  = flag.Bool("combine", false, "Produce one tag per file carrying the digests of every algorithm")
+	preload  = flag.Bool("preload", false, "Also produce a preload tag and Link header value for each file")
+	outPath  = flag.String("out", "", "Name of output file")
+	expect   = flag.String("expect", "", "Integrity attribute value every file must match, as a browser would check it")
+	name     = flag.String("name", "", "Name to identify and tag content read from stdin ('-') with")
+
+	targetType = flag.String("type", sri.SourceAuto, "Type every target must be, rather than classifying each. Valid: auto, url, file, dir, archive, data, stdin")
+
+	outputTemplate = flag.String("output-template", "", "text/template file to render the output with, in place of JSON")
+
+	include  = flag.String("include", "", "Comma separated glob patterns of files to hash within directories")
+	exclude  = flag.String("exclude", "", "Comma separated glob patterns of files to skip within directories")
+	symlinks = flag.String("symlinks", sri.SymlinksSkip, "Policy for symlinks within directories. Valid: skip, follow, error")
+
+	concurrency = flag.Int("concurrency", runtime.NumCPU(), "Maximum number of files to hash at once")
+	keepGoing   = flag.Bool("keep-going", false, "Continue after a target fails, reporting every failure at the end")
+
+	httpOpts = addHTTPFlags(flag.CommandLine)
+	tagOpts  = addTagFlags(flag.CommandLine)
+	urlOpts  = addURLFlags(flag.CommandLine)
+
+	commands = map[string]func(args []string){
+		"audit":       audit,
+		"check":       check,
+		"csp":         csp,
+		"deployed":    deployed,
+		"fingerprint": fingerprint,
+		"importmap":   importmap,
+		"inject":      inject,
+		"verify":      verify,
+	}
+)
+
+func main() {
+	// Subcommands take their own flags, so are dispatched before the top-level flags are parsed.
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			cmd(os.Args[2:])
+			return
+		}
+	}
+
+	targets := parseArgs(flag.CommandLine, os.Args[1:])
+
+	if err := sri.ValidateHash(*hashAlgo); err != nil {
+		log.Fatalf("[sri] Invalid value for flag '-hash'. %q", err)
+	}
+
+	if err := sri.ValidateType(*targetType); err != nil {
+		log.Fatalf("[sri] Invalid value for flag '-type'. %q", err)
+	}
+
+	// If comparison flag specified, run a comparison between the targets and exit(0) if they all match or exit(1)
+	// if any digests differ. Digests are also printed to stdout in both cases.
+	if *compare {
+		runCompare(targets)
+	}
+
+	// If we aren't in comparison mode, we are in 'generate' mode, and will attempt to produce
+	// SRIs for our given target and write them to either stdout or a file (if an outfile was provided)
+	if err := validateGenerate(targets); err != nil {
+		log.Fatalf("[sri] Unable to generate SRI output. %q", err)
+	}
+
+	if *expect != "" && len(sri.ParseMetadata(*expect)) == 0 {
+		log.Printf("[sri] Warning: '-expect' has no valid integrity metadata, so every file will match it")
+	}
+
+	g := &sri.Generator{
+		Hash:        *hashAlgo,
+		Combine:     *combine,
+		Preload:     *preload,
+		Include:     splitList(*include),
+		Exclude:     splitList(*exclude),
+		Symlinks:    *symlinks,
+		Concurrency: *concurrency,
+		KeepGoing:   *keepGoing,
+		StdinName:   *name,
+		Type:        *targetType,
+		Expect:      *expect,
+	}
+	if err := httpOpts.configure(g); err != nil {
+		log.Fatalf("[sri] Invalid HTTP configuration. %q", err)
+	}
+
+	urlOpts.configure(g)
+
+	tags, err := tagOpts.renderer(g.CrossOrigin)
+	if err != nil {
+		log.Fatalf("[sri] Invalid tag configuration. %q", err)
+	}
+	g.Tags = tags
+
+	if g.TagTemplate, err = tagOpts.template(); err != nil {
+		log.Fatalf("[sri] Invalid tag template. %q", err)
+	}
+
+	fis, err := g.GenerateContext(interruptContext(), targets)
+	if err != nil && len(fis) == 0 {
+		log.Fatalf("[sri] An error occured to generating SRI output. %s", formatErr(err))
+	}
+	warnCORS(fis)
+
+	if *outputTemplate != "" {
+		if err := writeTemplateOutput(g, fis, *outputTemplate, *outPath); err != nil {
+			log.Fatalf("[sri] An error occured rendering output template. %q", err)
+		}
+	} else if *outPath != "" {
+		if err := writeOutputToFile(fis, *outPath); err != nil {
+			log.Fatalf("[sri] An error occured writing SRIs to file. %q", err)
+		}
+	} else {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+
+		if err := enc.Encode(fis); err != nil {
+			log.Fatalf("An error occured writing SRIs to stdout")
+		}
+	}
+
+	// With '-keep-going', whatever could be generated is written before reporting the targets which failed
+	if err != nil {
+		log.Fatalf("[sri] Some targets failed to generate. %s", formatErr(err))
+	}
+
+	os.Exit(0)
+}
+
+// parseArgs parses the flags of fs, returning the targets between them. Unlike fs.Parse, flags may follow targets,
+// e.g `sri - -name app.js`.
+func parseArgs(fs *flag.FlagSet, args []string) []string {
+	targets := []string{}
+	for {
+		fs.Parse(args)
+		args = fs.Args()
+		if len(args) == 0 {
+			return targets
+		}
+
+		targets = append(targets, args[0])
+		args = args[1:]
+	}
+}
+
+// interruptContext returns a context cancelled on the first interrupt signal, so in-flight work can be abandoned.
+func interruptContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	go func() {
+		<-sig
+		cancel()
+	}()
+
+	return ctx
+}
+
+// formatErr lists each of the errors of a failed generation on its own line.
+func formatErr(err error) string {
+	errs, ok := err.(sri.Errors)
+	if !ok {
+		return fmt.Sprintf("%q", err)
+	}
+
+	lines := make([]string, len(errs))
+	for i, e := range errs {
+		lines[i] = fmt.Sprintf("\n\t%s", e)
+	}
+
+	return strings.Join(lines, "")
+}
+
+// writeTemplateOutput renders the output template to outPath, or to stdout if no outPath was provided.
+func writeTemplateOutput(g *sri.Generator, fis []sri.Integrity, templatePath, outPath string) error {
+	t, err := sri.ParseTemplate(templatePath)
+	if err != nil {
+		return err
+	}
+
+	w := os.Stdout
+	if outPath != "" {
+		f, err := os.Create(outPath)
+		if err != nil {
+			return fmt.Errorf("Unable to create file at location: %s. %s", outPath, err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	return g.WriteTemplate(w, t, fis)
+}
+
+func writeOutputToFile(fis []sri.Integrity, outPath string) error {
+	f, err := os.Create(outPath)
+	if err != nil {
+		return fmt.Errorf("Unable to create file at location: %s. %s", outPath, err)
+	}
+	defer f.Close()
+
+	return sri.WriteManifest(f, fis)
+}
+
+// splitList splits a comma separated flag value, dropping any empty items.
+func splitList(s string) []string {
+	items := []string{}
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+func validateGenerate(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("No target specified for SRI generation")
+	}
+
+	if args[0] == "" {
+		return fmt.Errorf("Received an empty target for SRI generation")
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// cliArgsEnv holds the arguments, separated by newlines, to run the CLI with in place of the tests.
+const cliArgsEnv = "SRI_TEST_CLI_ARGS"
+
+func TestMain(m *testing.M) {
+	if args := os.Getenv(cliArgsEnv); args != "" {
+		os.Args = append([]string{"sri"}, strings.Split(args, "\n")...)
+		main()
+		os.Exit(0)
+	}
+
+	os.Exit(m.Run())
+}
+
+// runCLI runs the CLI with args in a separate process, as commands exit once they are done, returning its combined
+// output and whether it exited(0).
+func runCLI(args ...string) (string, bool) {
+	cmd := exec.Command(os.Args[0])
+	cmd.Env = append(os.Environ(), cliArgsEnv+"="+strings.Join(args, "\n"))
+	out, err := cmd.CombinedOutput()
+
+	return string(out), err == nil
+}
+
+func TestValidateGenerate(t *testing.T) {
+	type testCase struct {
+		inputs []string
+		errMsg string
+	}
+
+	testCases := []testCase{
+		{
+			inputs: []string{},
+			errMsg: "No target specified for SRI generation",
+		},
+		{
+			inputs: []string{""},
+			errMsg: "Received an empty target for SRI generation",
+		},
+		{
+			inputs: []string{"one input"},
+			errMsg: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		err := validateGenerate(tc.inputs)
+
+		if err == nil && tc.errMsg != "" {
+			t.Fatalf("Expected an error from validateGenerate call")
+		}
+
+		if tc.errMsg == "" && err != nil {
+			t.Fatalf("Unexpected an error from validateCompare call. %q", err)
+		}
+
+		if err != nil && err.Error() != tc.errMsg {
+			t.Fatalf("Expected error message of %s. Got %q", tc.errMsg, err)
+		}
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	type testCase struct {
+		input string
+		exp   []string
+	}
+
+	testCases := []testCase{
+		{"", []string{}},
+		{"*.js", []string{"*.js"}},
+		{"*.js, *.css,,", []string{"*.js", "*.css"}},
+	}
+
+	for _, tc := range testCases {
+		if items := splitList(tc.input); !reflect.DeepEqual(items, tc.exp) {
+			t.Fatalf("Expected %q from %q. Got %q", tc.exp, tc.input, items)
+		}
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	name := fs.String("name", "", "")
+	combine := fs.Bool("combine", false, "")
+
+	targets := parseArgs(fs, []string{"-combine", "-", "-name", "app.js", "dist/"})
+
+	if exp := []string{"-", "dist/"}; !reflect.DeepEqual(targets, exp) {
+		t.Fatalf("Expected targets %q. Got %q", exp, targets)
+	}
+
+	if *name != "app.js" || !*combine {
+		t.Fatalf("Expected flags following targets to be parsed. Got name %q and combine %t", *name, *combine)
+	}
+}
+
+func TestSubcommandFlagsFollowTargets(t *testing.T) {
+	srv := httptest.NewServer(http.StripPrefix("/static/", http.FileServer(http.Dir("../../test"))))
+	defer srv.Close()
+
+	page := "<script src=\"../../test/test.js\" integrity=\"sha256-jEUM4jWrIiMerWo9zYrx6XwQ5eI77uzuETBptBvPlRQ=\"></script>"
+	pagePath := "page_test.html"
+	if err := ioutil.WriteFile(pagePath, []byte(page), 0644); err != nil {
+		t.Fatalf("Unable to write test page. %q", err)
+	}
+	defer os.Remove(pagePath)
+
+	testCases := [][]string{
+		{"check", "../../test/test.js", "sha256-jEUM4jWrIiMerWo9zYrx6XwQ5eI77uzuETBptBvPlRQ=", "-timeout", "1s"},
+		{"audit", pagePath, "-format", "json", "-root", "."},
+		{"deployed", "../../test", "-base-url", srv.URL + "/static/", "-format", "json"},
+		{"fingerprint", "../../test", "-dry-run"},
+	}
+
+	for _, args := range testCases {
+		if out, ok := runCLI(args...); !ok {
+			t.Fatalf("Expected %q to succeed with flags after its targets. Got:\n%s", args, out)
+		}
+	}
+
+	if out, ok := runCLI("audit", pagePath, "-format", "yaml"); ok || !strings.Contains(out, "-format") {
+		t.Fatalf("Expected flags after the targets of audit to be parsed. Got:\n%s", out)
+	}
+}
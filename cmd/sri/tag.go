@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/template"
+
+	"github.com/sHesl/sri"
+)
+
+// tagFlags configure how generated tags are rendered.
+type tagFlags struct {
+	config         *string
+	templatePath   *string
+	referrerPolicy *string
+	module         *bool
+	noModule       *bool
+	async          *bool
+	deferScript    *bool
+	quote          *string
+	xhtml          *bool
+}
+
+func addTagFlags(fs *flag.FlagSet) *tagFlags {
+	t := &tagFlags{}
+	t.config = fs.String("tag-config", "", "JSON file of default tag options and per file pattern rules")
+	t.templatePath = fs.String("tag-template", "", "text/template file to render tags with, or a builtin. Valid builtins: html, jinja, erb, jsx, gotemplate")
+	t.referrerPolicy = fs.String("referrerpolicy", "", "referrerpolicy attribute of tags")
+	t.module = fs.Bool("module", false, "Render scripts with type=\"module\"")
+	t.noModule = fs.Bool("nomodule", false, "Render scripts with the nomodule attribute")
+	t.async = fs.Bool("async", false, "Render scripts with the async attribute")
+	t.deferScript = fs.Bool("defer", false, "Render scripts with the defer attribute")
+	t.quote = fs.String("quote", sri.QuoteSingle, "Quote style of attribute values. Valid: single, double")
+	t.xhtml = fs.Bool("xhtml", false, "Render XHTML; self-closing link tags and valued boolean attributes")
+
+	return t
+}
+
+// renderer builds a TagRenderer from the flags, layering the '-tag-config' file on top if provided; options the
+// config doesn't set, in its default or in any of its rules, keep the values of the flags. The crossorigin mode is
+// shared with the CORS check of downloads.
+func (t *tagFlags) renderer(crossOrigin string) (*sri.TagRenderer, error) {
+	tr := &sri.TagRenderer{
+		Default: sri.TagOptions{
+			CrossOrigin:    crossOrigin,
+			ReferrerPolicy: *t.referrerPolicy,
+			Module:         *t.module,
+			NoModule:       *t.noModule,
+			Async:          *t.async,
+			Defer:          *t.deferScript,
+			Quote:          *t.quote,
+			XHTML:          *t.xhtml,
+		},
+	}
+
+	if *t.config != "" {
+		if err := t.applyConfig(tr); err != nil {
+			return nil, fmt.Errorf("Unable to parse %s. %s", *t.config, err)
+		}
+	}
+
+	return tr, tr.Validate()
+}
+
+// applyConfig decodes the '-tag-config' file over the options of tr, with each rule starting from the default.
+func (t *tagFlags) applyConfig(tr *sri.TagRenderer) error {
+	f, err := os.Open(*t.config)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var config struct {
+		Default json.RawMessage   `json:"default"`
+		Rules   []json.RawMessage `json:"rules"`
+	}
+	if err := json.NewDecoder(f).Decode(&config); err != nil {
+		return err
+	}
+
+	if len(config.Default) > 0 {
+		if err := json.Unmarshal(config.Default, &tr.Default); err != nil {
+			return err
+		}
+	}
+
+	for _, raw := range config.Rules {
+		rule := sri.TagRule{TagOptions: tr.Default}
+		if err := json.Unmarshal(raw, &rule); err != nil {
+			return err
+		}
+		tr.Rules = append(tr.Rules, rule)
+	}
+
+	return nil
+}
+
+// template parses the '-tag-template', if one was provided.
+func (t *tagFlags) template() (*template.Template, error) {
+	if *t.templatePath == "" {
+		return nil, nil
+	}
+
+	return sri.ParseTemplate(*t.templatePath)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/sHesl/sri"
+)
+
+func TestTagConfigLayersOnFlags(t *testing.T) {
+	f, err := ioutil.TempFile(".", "sri")
+	if err != nil {
+		t.Fatalf("Unable to create tag config. %q", err)
+	}
+	defer os.Remove(f.Name())
+
+	config := `{"default": {"defer": true}, "rules": [{"pattern": "*.mjs", "module": true}]}`
+	if _, err := f.WriteString(config); err != nil {
+		t.Fatalf("Unable to write tag config. %q", err)
+	}
+	f.Close()
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	tf := addTagFlags(fs)
+
+	if err := fs.Parse([]string{"-tag-config", f.Name(), "-quote", "double", "-xhtml"}); err != nil {
+		t.Fatalf("Unexpected error parsing flags. %q", err)
+	}
+
+	tr, err := tf.renderer(sri.CrossOriginUseCredentials)
+	if err != nil {
+		t.Fatalf("Unexpected error from renderer call. %q", err)
+	}
+
+	exp := `<script src="app.js" integrity="sha256-abc" crossorigin="use-credentials" defer="defer"></script>`
+	if tag := tr.Render("app.js", "sha256-abc"); tag != exp {
+		t.Fatalf("Expected the flags to apply alongside the config default. Expected %s. Got %s", exp, tag)
+	}
+
+	exp = `<script src="app.mjs" integrity="sha256-abc" crossorigin="use-credentials" type="module" defer="defer"></script>`
+	if tag := tr.Render("app.mjs", "sha256-abc"); tag != exp {
+		t.Fatalf("Expected rules to inherit the flags and config default. Expected %s. Got %s", exp, tag)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/sHesl/sri"
+)
+
+// urlFlags are the flags mapping local files to the public URLs they are served from.
+type urlFlags struct {
+	baseURL  *string
+	rewrites rewriteFlag
+	envs     envFlag
+}
+
+func addURLFlags(fs *flag.FlagSet) *urlFlags {
+	u := &urlFlags{envs: envFlag{}}
+	u.baseURL = fs.String("base-url", "", "Public URL local files are served from, used in tags in place of their path, e.g 'https://cdn.com/static/'")
+	fs.Var(&u.rewrites, "rewrite", "Prefix of local paths to replace in tags, as 'from=to', e.g 'dist/=https://cdn.com/'. May be repeated")
+	fs.Var(u.envs, "env", "Named environment to also produce tags for, as 'name=base-url'. May be repeated")
+
+	return u
+}
+
+// configure applies the flags to g.
+func (u *urlFlags) configure(g *sri.Generator) {
+	if *u.baseURL != "" || len(u.rewrites) > 0 {
+		g.URLs = &sri.URLMap{BaseURL: *u.baseURL, Rewrites: u.rewrites}
+	}
+
+	if len(u.envs) > 0 {
+		g.Environments = make(map[string]*sri.URLMap)
+		for name, baseURL := range u.envs {
+			g.Environments[name] = &sri.URLMap{BaseURL: baseURL}
+		}
+	}
+}
+
+// rewriteFlag collects repeated '-rewrite' flags.
+type rewriteFlag []sri.URLRewrite
+
+func (r *rewriteFlag) String() string {
+	return fmt.Sprint([]sri.URLRewrite(*r))
+}
+
+func (r *rewriteFlag) Set(value string) error {
+	rewrite, err := sri.ParseURLRewrite(value)
+	if err != nil {
+		return err
+	}
+
+	*r = append(*r, rewrite)
+	return nil
+}
+
+// envFlag collects repeated '-env' flags.
+type envFlag map[string]string
+
+func (e envFlag) String() string {
+	return fmt.Sprint(map[string]string(e))
+}
+
+func (e envFlag) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return fmt.Errorf("Expected environment of the form 'name=base-url'. Got '%s'", value)
+	}
+
+	e[parts[0]] = parts[1]
+	return nil
+}
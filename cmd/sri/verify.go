@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/sHesl/sri"
+)
+
+// verify re-hashes every file in a manifest written by '-out', exiting(1) if any file has drifted, gone missing or
+// been added. Any targets provided are hashed in place of the sources recorded in the manifest.
+func verify(args []string) {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	manifestPath := fs.String("manifest", "sri.json", "Path of the manifest to verify")
+	root := fs.String("root", ".", "Directory that the sources of local files in the manifest are relative to")
+	httpOpts := addHTTPFlags(fs)
+	targets := parseArgs(fs, args)
+
+	f, err := os.Open(*manifestPath)
+	if err != nil {
+		log.Fatalf("[sri] Unable to open manifest. %q", err)
+	}
+
+	m, err := sri.ReadManifest(f)
+	f.Close()
+	if err != nil {
+		log.Fatalf("[sri] Unable to read manifest. %q", err)
+	}
+
+	g := &sri.Generator{Hash: sri.AllHashes}
+	if err := httpOpts.configure(g); err != nil {
+		log.Fatalf("[sri] Invalid HTTP configuration. %q", err)
+	}
+
+	var result sri.VerifyResult
+	if len(targets) > 0 {
+		result = g.Verify(m, targets)
+	} else {
+		result = g.VerifySources(m, *root)
+	}
+
+	for target, err := range result.Errors {
+		fmt.Printf("error    %s - %s\n", target, err)
+	}
+	printNames("drifted", result.Drifted)
+	printNames("missing", result.Missing)
+	printNames("new", result.New)
+
+	if !result.OK() {
+		fmt.Println("Manifest did not match")
+		os.Exit(1)
+	}
+
+	fmt.Println("Manifest matches")
+	os.Exit(0)
+}
+
+func printNames(label string, names []string) {
+	for _, name := range names {
+		fmt.Printf("%-8s %s\n", label, name)
+	}
+}
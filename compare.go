@@ -1,34 +1,120 @@
-package main
+package sri
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
-// comparison runs a sha256 comparison against the two provided targets, returning the equality of their hashes,
+// Compare runs a sha256 comparison against the two provided targets, returning the equality of their hashes,
 // as well as their individual digests and any resulting errors.
-func comparison(a, b string) (bool, string, string, error) {
-	fis, err := generate([]string{a, b}, sha256Algo)
+func Compare(a, b string) (bool, string, string, error) {
+	return (&Generator{Hash: SHA256}).Compare(a, b)
+}
+
+// Compare runs a sha256 comparison against the two provided targets using the Generator's settings for walking
+// directories and downloading.
+func (g *Generator) Compare(a, b string) (bool, string, string, error) {
+	c := *g
+	c.Hash = SHA256
+
+	comparison, err := c.CompareAll([]string{a, b})
 	if err != nil {
-		return false, "", "", err
+		return false, "", "", fmt.Errorf("Unable to produce both integrities for %q", []string{a, b})
 	}
 
-	if len(fis) != 2 {
-		return false, "", "", fmt.Errorf("Unable to produce both integrities for %q", []string{a, b})
+	return comparison.Match, comparison.Digests[a], comparison.Digests[b], nil
+}
+
+// Comparison is the result of comparing any number of targets. Digests holds the digests of each target, separated
+// by spaces, while Groups gathers the targets sharing the same digests, in the order they were first seen. Expected
+// is the integrity attribute value the targets were compared against, if any.
+type Comparison struct {
+	Match    bool              `json:"match"`
+	Expected string            `json:"expected,omitempty"`
+	Digests  map[string]string `json:"digests"`
+	Groups   []ComparisonGroup `json:"groups"`
+}
+
+// ComparisonGroup is a set of targets sharing the same digests.
+type ComparisonGroup struct {
+	Digest  string   `json:"digest"`
+	Targets []string `json:"targets"`
+}
+
+// CompareAll compares the digests of every target for each hashing algorithm of the Generator (sha256 if none is
+// set), using its settings for downloading. Each target must be a single file, URL or stdin; the targets match if
+// every one of their digests are equal.
+func (g *Generator) CompareAll(targets []string) (*Comparison, error) {
+	c := *g
+	c.Combine = false
+	c.Expect = ""
+	if c.Hash == "" {
+		c.Hash = SHA256
 	}
+	g = &c
 
-	return fis[0].Digest == fis[1].Digest, fis[0].Digest, fis[1].Digest, nil
+	comparison := &Comparison{Digests: make(map[string]string), Groups: []ComparisonGroup{}}
+	groups := make(map[string]int)
+
+	var errs Errors
+	for _, target := range targets {
+		if _, ok := comparison.Digests[target]; ok {
+			return nil, fmt.Errorf("Received %s more than once for comparison", target)
+		}
+
+		// Each target is generated separately, as they may well share the same file name
+		fis, err := g.Generate([]string{target})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("Unable to produce the integrity of %s. %s", target, err))
+			continue
+		}
+
+		if files := len(fis) / len(hashNames(g.Hash)); files != 1 {
+			errs = append(errs, fmt.Errorf("Unable to compare %s. Expected a single file, got %d", target, files))
+			continue
+		}
+
+		digests := joinDigests(fis)
+		comparison.Digests[target] = digests
+
+		i, ok := groups[digests]
+		if !ok {
+			i = len(comparison.Groups)
+			groups[digests] = i
+			comparison.Groups = append(comparison.Groups, ComparisonGroup{Digest: digests})
+		}
+		comparison.Groups[i].Targets = append(comparison.Groups[i].Targets, target)
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	comparison.Match = len(comparison.Groups) == 1
+	return comparison, nil
 }
 
-func validateCompare(args []string) error {
-	if len(args) != 2 {
-		return fmt.Errorf("Expected two targets to be specified for comparison")
+// CompareExpected compares every target against an expected integrity attribute value, hashing them with the
+// algorithm a browser would check it with. The targets match if a browser would accept each of them, following the
+// semantics of Match; so an expected value with no valid metadata matches anything.
+func (g *Generator) CompareExpected(targets []string, integrity string) (*Comparison, error) {
+	c := *g
+	if algo := strongestAlgorithm(integrity); algo != "" {
+		c.Hash = algo
 	}
 
-	if args[0] == "" || args[1] == "" {
-		return fmt.Errorf("Received an empty target for comparison")
+	comparison, err := c.CompareAll(targets)
+	if err != nil {
+		return nil, err
 	}
 
-	if args[0] == args[1] {
-		return fmt.Errorf("Received two indentical inputs for comparison")
+	comparison.Expected = integrity
+	comparison.Match = true
+	for _, target := range targets {
+		if !matchDigests(integrity, strings.Fields(comparison.Digests[target])) {
+			comparison.Match = false
+		}
 	}
 
-	return nil
+	return comparison, nil
 }
@@ -1,12 +1,15 @@
-package main
+package sri
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+)
 
 func TestCompare(t *testing.T) {
-	same, a, b, err := comparison("test/compare-same-a.js", "test/compare-same-b.js")
+	same, a, b, err := Compare("test/compare-same-a.js", "test/compare-same-b.js")
 
 	if err != nil {
-		t.Fatalf("Unexpected error from comparison call. %q", err)
+		t.Fatalf("Unexpected error from Compare call. %q", err)
 	}
 
 	if !same {
@@ -20,10 +23,10 @@ func TestCompare(t *testing.T) {
 }
 
 func TestCompareFail(t *testing.T) {
-	same, a, b, err := comparison("test/compare-diff-a.js", "test/compare-diff-b.js")
+	same, a, b, err := Compare("test/compare-diff-a.js", "test/compare-diff-b.js")
 
 	if err != nil {
-		t.Fatalf("Unexpected error from comparison call. %q", err)
+		t.Fatalf("Unexpected error from Compare call. %q", err)
 	}
 
 	if same {
@@ -55,10 +58,10 @@ func TestCompareHandlesErrors(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		same, a, b, err := comparison(tc.aInput, tc.bInput)
+		same, a, b, err := Compare(tc.aInput, tc.bInput)
 
 		if err == nil {
-			t.Fatalf("Expected an error from comparison call")
+			t.Fatalf("Expected an error from Compare call")
 		}
 
 		if err.Error() != tc.errMsg {
@@ -66,7 +69,7 @@ func TestCompareHandlesErrors(t *testing.T) {
 		}
 
 		if same {
-			t.Fatalf("Expected comparison call to return false on error")
+			t.Fatalf("Expected Compare call to return false on error")
 		}
 
 		if a != "" || b != "" {
@@ -75,52 +78,59 @@ func TestCompareHandlesErrors(t *testing.T) {
 	}
 }
 
-func TestValidateCompare(t *testing.T) {
-	type testCase struct {
-		inputs []string
-		errMsg string
+func TestCompareAll(t *testing.T) {
+	g := &Generator{Hash: "sha256,sha384"}
+	targets := []string{"test/compare-same-a.js", "test/compare-diff-a.js", "test/compare-same-b.js"}
+
+	comparison, err := g.CompareAll(targets)
+	if err != nil {
+		t.Fatalf("Unexpected error from CompareAll call. %q", err)
 	}
 
-	testCases := []testCase{
-		{
-			inputs: []string{"only one input"},
-			errMsg: "Expected two targets to be specified for comparison",
-		},
-		{
-			inputs: []string{"two inputs", "two different inputs"},
-			errMsg: "",
-		},
-		{
-			inputs: []string{"two inputs", ""},
-			errMsg: "Received an empty target for comparison",
-		},
-		{
-			inputs: []string{"two identical inputs", "two identical inputs"},
-			errMsg: "Received two indentical inputs for comparison",
-		},
-		{
-			inputs: []string{"", "two inputs"},
-			errMsg: "Received an empty target for comparison",
-		},
-		{
-			inputs: []string{"three inputs", "three inputs", "three inputs"},
-			errMsg: "Expected two targets to be specified for comparison",
-		},
+	if comparison.Match {
+		t.Fatalf("Expected comparison of %q to be false", targets)
 	}
 
-	for _, tc := range testCases {
-		err := validateCompare(tc.inputs)
+	same := "sha256-hwj4HVJ7OFOzPES8HffZ4IySCiQq7P/+1RT9YQJMAXs= " +
+		"sha384-6yKRlQqq9r5dU0GEivGoDai04RH+ufhfO1htclXkbjdJ+184pc1rRrsWhk3aDf3D"
+	exp := []ComparisonGroup{
+		{Digest: same, Targets: []string{"test/compare-same-a.js", "test/compare-same-b.js"}},
+		{Digest: comparison.Digests["test/compare-diff-a.js"], Targets: []string{"test/compare-diff-a.js"}},
+	}
 
-		if err == nil && tc.errMsg != "" {
-			t.Fatalf("Expected an error from validateCompare call")
-		}
+	if !reflect.DeepEqual(comparison.Groups, exp) {
+		t.Fatalf("Expected groups %+v. Got %+v", exp, comparison.Groups)
+	}
 
-		if tc.errMsg == "" && err != nil {
-			t.Fatalf("Unexpected an error from validateCompare call. %q", err)
+	if _, err := g.CompareAll([]string{"test/compare-same-a.js", "test"}); err == nil {
+		t.Fatalf("Expected an error comparing a directory")
+	}
+}
+
+func TestCompareExpected(t *testing.T) {
+	type testCase struct {
+		targets  []string
+		expected string
+		match    bool
+	}
+
+	sha384 := "sha384-zBTHeP/UZLYRhjvTi7r3Dx7MTCNf/ddGENI26AacmrgqzH8YOkA+EJ14MXpwD4wL"
+	tcs := map[string]testCase{
+		"match":                    {[]string{"test/test.js"}, sha384, true},
+		"strongest algorithm only": {[]string{"test/test.js"}, "sha256-jEUM4jWrIiMerWo9zYrx6XwQ5eI77uzuETBptBvPlRQ= sha384-notthedigest", false},
+		"any strongest digest":     {[]string{"test/test.js"}, "sha384-notthedigest " + sha384 + "?opt", true},
+		"every target must match":  {[]string{"test/test.js", "test/test.min.js"}, sha384, false},
+		"no valid metadata":        {[]string{"test/test.js"}, "md5-whatever", true},
+	}
+
+	for name, tc := range tcs {
+		comparison, err := (&Generator{Hash: SHA256}).CompareExpected(tc.targets, tc.expected)
+		if err != nil {
+			t.Fatalf("%s: Unexpected error from CompareExpected call. %q", name, err)
 		}
 
-		if err != nil && err.Error() != tc.errMsg {
-			t.Fatalf("Expected error message of %s. Got %q", tc.errMsg, err)
+		if comparison.Match != tc.match {
+			t.Fatalf("%s: Expected match to be %t. Got %+v", name, tc.match, comparison)
 		}
 	}
 }
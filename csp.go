@@ -0,0 +1,215 @@
+package sri
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const (
+	CSPScriptSrc = "script-src"
+	CSPStyleSrc  = "style-src"
+)
+
+// InlineBlock is an inline script or style element of an HTML document, along with the CSP hash sources (e.g
+// 'sha256-...') that allow it to run.
+type InlineBlock struct {
+	Page      string   `json:"page,omitempty"`
+	Line      int      `json:"line"`
+	Directive string   `json:"directive"`
+	Size      int64    `json:"size"`
+	Sources   []string `json:"sources"`
+}
+
+// CSP collects the hash sources needed to allow the scripts and styles of one or more HTML documents under a
+// Content-Security-Policy.
+type CSP struct {
+	ScriptSrc []string `json:"script-src"`
+	StyleSrc  []string `json:"style-src"`
+
+	// StrictDynamic adds 'strict-dynamic' to script-src, so scripts loaded by the hashed scripts are trusted too.
+	// Hosts are ignored by browsers supporting it, so external scripts need their hashes adding with AddSources.
+	StrictDynamic bool `json:"strict_dynamic,omitempty"`
+
+	// RequireSRIFor adds a require-sri-for directive, asking browsers which support it to block any script or
+	// stylesheet loaded without an integrity attribute.
+	RequireSRIFor bool `json:"require_sri_for,omitempty"`
+}
+
+// InlineBlocks hashes the contents of every inline script and style element of an HTML document, as a browser does
+// when checking them against the hash sources of a Content-Security-Policy.
+func (g *Generator) InlineBlocks(doc []byte) ([]InlineBlock, error) {
+	blocks := []InlineBlock{}
+
+	for _, tag := range scanTags(doc) {
+		directive, ok := tag.inlineDirective()
+		if !ok {
+			continue
+		}
+
+		digests, n, err := g.digests(bytes.NewReader(normalizeNewlines(doc[tag.TextStart:tag.TextEnd])))
+		if err != nil {
+			return nil, fmt.Errorf("Unable to hash inline %s on line %d. %s", tag.Name, tag.Line, err)
+		}
+
+		blocks = append(blocks, InlineBlock{
+			Line:      tag.Line,
+			Directive: directive,
+			Size:      n,
+			Sources:   hashSources(digests),
+		})
+	}
+
+	return blocks, nil
+}
+
+// ExternalScriptSources produces hash sources for every script an HTML document references, so they can be
+// allowed alongside 'strict-dynamic'. References are resolved as they are by Inject.
+func (g *Generator) ExternalScriptSources(doc []byte, dir, root string) ([]string, error) {
+	sources := []string{}
+	errs := Errors{}
+
+	for _, tag := range scanTags(doc) {
+		ref, ok := tag.subresource()
+		if !ok || tag.Name != "script" {
+			continue
+		}
+
+		target, remote, ok := resolveRef(ref, dir, root)
+		if !ok {
+			continue
+		}
+
+		fis, err := g.resolved(target, remote)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		digests := make([]string, len(fis))
+		for i, fi := range fis {
+			digests[i] = fi.Digest
+		}
+		sources = append(sources, hashSources(digests)...)
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return sources, nil
+}
+
+// Add adds the hash sources of inline blocks to the matching directives of the policy.
+func (c *CSP) Add(blocks ...InlineBlock) {
+	for _, b := range blocks {
+		c.AddSources(b.Directive, b.Sources...)
+	}
+}
+
+// AddSources adds sources to a directive of the policy, skipping any it already has.
+func (c *CSP) AddSources(directive string, sources ...string) {
+	list := &c.ScriptSrc
+	if directive == CSPStyleSrc {
+		list = &c.StyleSrc
+	}
+
+	for _, s := range sources {
+		if !containsString(*list, s) {
+			*list = append(*list, s)
+		}
+	}
+}
+
+// String renders the policy as a Content-Security-Policy header value fragment, e.g
+// "script-src 'sha256-...'; style-src 'sha256-...'".
+func (c *CSP) String() string {
+	directives := []string{}
+
+	scriptSrc := c.ScriptSrc
+	if c.StrictDynamic {
+		scriptSrc = append(append([]string{}, scriptSrc...), "'strict-dynamic'")
+	}
+
+	if len(scriptSrc) > 0 {
+		directives = append(directives, CSPScriptSrc+" "+strings.Join(scriptSrc, " "))
+	}
+
+	if len(c.StyleSrc) > 0 {
+		directives = append(directives, CSPStyleSrc+" "+strings.Join(c.StyleSrc, " "))
+	}
+
+	if c.RequireSRIFor {
+		directives = append(directives, "require-sri-for script style")
+	}
+
+	return strings.Join(directives, "; ")
+}
+
+// inlineDirective returns the directive governing an inline script or style element, if the tag is one. Scripts
+// with a src, and data blocks such as <script type="application/json">, are never checked against a policy.
+func (t htmlTag) inlineDirective() (string, bool) {
+	switch t.Name {
+	case "script":
+		if _, ok := t.attr("src"); ok {
+			return "", false
+		}
+
+		if !executableScriptType(t) {
+			return "", false
+		}
+
+		return CSPScriptSrc, true
+	case "style":
+		return CSPStyleSrc, true
+	}
+
+	return "", false
+}
+
+// executableScriptType reports whether a browser runs (or, for import maps and speculation rules, parses) a script
+// element, and so checks it against script-src. As in the HTML standard, a missing or empty type, or any JavaScript
+// MIME type essence, is a classic script, with a legacy language attribute standing in for a missing type.
+func executableScriptType(t htmlTag) bool {
+	typ, hasType := t.attr("type")
+	value := typ.Value
+	if !hasType {
+		if lang, ok := t.attr("language"); ok && lang.Value != "" {
+			value = "text/" + lang.Value
+		}
+	}
+
+	switch value = strings.ToLower(strings.TrimSpace(value)); value {
+	case "", "module", "importmap", "speculationrules":
+		return true
+	}
+
+	return javascriptTypes[value]
+}
+
+// normalizeNewlines converts CRLF and lone CR line endings to LF, as the HTML parser does before a browser hashes
+// the contents of an element.
+func normalizeNewlines(b []byte) []byte {
+	b = bytes.Replace(b, []byte("\r\n"), []byte("\n"), -1)
+	return bytes.Replace(b, []byte("\r"), []byte("\n"), -1)
+}
+
+// hashSources quotes digests as CSP hash sources.
+func hashSources(digests []string) []string {
+	sources := make([]string, len(digests))
+	for i, d := range digests {
+		sources[i] = "'" + d + "'"
+	}
+
+	return sources
+}
+
+func containsString(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+
+	return false
+}
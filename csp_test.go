@@ -0,0 +1,87 @@
+package sri
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestInlineBlocks(t *testing.T) {
+	doc := "<html>\r\n" +
+		"<script>alert(1)</script>\n" +
+		"<style>\r\nbody{}\r\n</style>\n" +
+		"<script type=\"application/json\">{}</script>\n" +
+		"<script src=\"test/test.js\"></script>\n" +
+		"<script type=module>alert(1)</SCRIPT>\n" +
+		"<script type=\" Text/JScript \">alert(1)</script>\n" +
+		"<script type=\"text/javascript; charset=utf-8\">alert(1)</script>\n" +
+		"<script language=\"LiveScript\">alert(1)</script>\n" +
+		"</html>"
+
+	g := &Generator{Hash: SHA256}
+	blocks, err := g.InlineBlocks([]byte(doc))
+	if err != nil {
+		t.Fatalf("Unexpected error from InlineBlocks call. %q", err)
+	}
+
+	exp := []InlineBlock{
+		{Line: 2, Directive: CSPScriptSrc, Size: 8, Sources: []string{"'sha256-bhHHL3z2vDgxUt0W3dWQOrprscmda2Y5pLsLg4GF+pI='"}},
+		{Line: 3, Directive: CSPStyleSrc, Size: 8, Sources: []string{"'sha256-OrBoaOgHKpM7E5sdbFVzvg4GOwbS7qyojeZwhuKtGDM='"}},
+		{Line: 8, Directive: CSPScriptSrc, Size: 8, Sources: []string{"'sha256-bhHHL3z2vDgxUt0W3dWQOrprscmda2Y5pLsLg4GF+pI='"}},
+		{Line: 9, Directive: CSPScriptSrc, Size: 8, Sources: []string{"'sha256-bhHHL3z2vDgxUt0W3dWQOrprscmda2Y5pLsLg4GF+pI='"}},
+		{Line: 11, Directive: CSPScriptSrc, Size: 8, Sources: []string{"'sha256-bhHHL3z2vDgxUt0W3dWQOrprscmda2Y5pLsLg4GF+pI='"}},
+	}
+
+	if !reflect.DeepEqual(blocks, exp) {
+		t.Fatalf("Expected blocks %+v. Got %+v", exp, blocks)
+	}
+}
+
+func TestCSPString(t *testing.T) {
+	type testCase struct {
+		csp CSP
+		exp string
+	}
+
+	tcs := map[string]testCase{
+		"empty": {
+			csp: CSP{},
+			exp: "",
+		},
+		"hashes": {
+			csp: CSP{ScriptSrc: []string{"'sha256-a'", "'sha256-b'"}, StyleSrc: []string{"'sha256-c'"}},
+			exp: "script-src 'sha256-a' 'sha256-b'; style-src 'sha256-c'",
+		},
+		"strict-dynamic and require-sri-for": {
+			csp: CSP{ScriptSrc: []string{"'sha256-a'"}, StrictDynamic: true, RequireSRIFor: true},
+			exp: "script-src 'sha256-a' 'strict-dynamic'; require-sri-for script style",
+		},
+	}
+
+	for name, tc := range tcs {
+		if got := tc.csp.String(); got != tc.exp {
+			t.Fatalf("%s: Expected %q. Got %q", name, tc.exp, got)
+		}
+	}
+}
+
+func TestExternalScriptSources(t *testing.T) {
+	doc := `<script src="/test/test.js"></script><link rel="stylesheet" href="/test/test.css"><script>inline</script>`
+
+	g := &Generator{Hash: SHA256}
+	sources, err := g.ExternalScriptSources([]byte(doc), "pages", ".")
+	if err != nil {
+		t.Fatalf("Unexpected error from ExternalScriptSources call. %q", err)
+	}
+
+	exp := []string{"'sha256-jEUM4jWrIiMerWo9zYrx6XwQ5eI77uzuETBptBvPlRQ='"}
+	if !reflect.DeepEqual(sources, exp) {
+		t.Fatalf("Expected sources %q. Got %q", exp, sources)
+	}
+
+	var c CSP
+	c.AddSources(CSPScriptSrc, sources...)
+	c.AddSources(CSPScriptSrc, sources...)
+	if len(c.ScriptSrc) != 1 {
+		t.Fatalf("Expected duplicate sources to be skipped. Got %q", c.ScriptSrc)
+	}
+}
@@ -0,0 +1,120 @@
+package sri
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	DeployedMatch    = "match"
+	DeployedMismatch = "mismatch"
+	DeployedNotFound = "not-found"
+	DeployedError    = "error"
+)
+
+// DeployedFile compares a local file with the copy deployed at its public URL. Local and Deployed are the digests
+// of each, separated by spaces.
+type DeployedFile struct {
+	File     string `json:"file"`
+	URL      string `json:"url"`
+	Status   string `json:"status"`
+	Local    string `json:"local,omitempty"`
+	Deployed string `json:"deployed,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Deployed pairs each file of dir with its public URL, mapped by URLs, and checks the deployed copy matches the
+// local file; hashing at most Concurrency files at once. Files are reported in the order they were found, each with
+// one of the Deployed statuses. A failure to hash one file never stops the others being checked.
+func (g *Generator) Deployed(ctx context.Context, dir string) ([]DeployedFile, error) {
+	if err := ValidateHash(g.Hash); err != nil {
+		return nil, err
+	}
+
+	if g.URLs == nil {
+		return nil, fmt.Errorf("Unable to check deployed files without the URLs they are served from")
+	}
+
+	files, err := g.walk(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]DeployedFile, len(files))
+
+	// Each mapped file is hashed by a pair of jobs; its local path, then its public URL
+	var jobs []job
+	var pairs []int
+	for i, wf := range files {
+		results[i] = DeployedFile{File: wf.Rel, Status: DeployedError}
+
+		u, ok := g.URLs.URL(wf.Rel, wf.Path)
+		if !ok {
+			results[i].Error = "No public URL. It matches no rewrite, and there is no base URL"
+			continue
+		}
+		results[i].URL = u
+
+		jobs = append(jobs, job{target: wf.Path, kind: SourceFile}, job{target: u, kind: SourceURL})
+		pairs = append(pairs, i)
+	}
+
+	c := *g
+	c.KeepGoing = true
+	c.URLs, c.Environments = nil, nil
+
+	fis, errs := c.runJobs(ctx, jobs)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	for p, i := range pairs {
+		local, deployed := 2*p, 2*p+1
+		results[i].compare(fis[local], errs[local], fis[deployed], errs[deployed])
+	}
+
+	return results, nil
+}
+
+// compare sets the status of f from the integrities, or errors, of the local file and its deployed copy.
+func (f *DeployedFile) compare(local []Integrity, localErr error, deployed []Integrity, deployedErr error) {
+	if localErr != nil {
+		f.Error = localErr.Error()
+		return
+	}
+	f.Local = joinDigests(local)
+
+	se, ok := deployedErr.(*StatusError)
+	if ok && (se.StatusCode == http.StatusNotFound || se.StatusCode == http.StatusGone) {
+		f.Status = DeployedNotFound
+		f.Error = se.Error()
+		return
+	} else if deployedErr != nil {
+		f.Error = deployedErr.Error()
+		return
+	}
+	f.Deployed = joinDigests(deployed)
+
+	// Without digests to compare, nothing has been shown to match
+	if f.Local == "" || f.Deployed == "" {
+		f.Status, f.Error = DeployedError, "No digests to compare"
+		return
+	}
+
+	f.Status = DeployedMismatch
+	if f.Local == f.Deployed {
+		f.Status = DeployedMatch
+	}
+}
+
+// joinDigests returns the digests of the integrities of a single file, separated by spaces.
+func joinDigests(fis []Integrity) string {
+	digests := make([]string, len(fis))
+	for i, fi := range fis {
+		digests[i] = fi.Digest
+	}
+
+	return strings.Join(digests, " ")
+}
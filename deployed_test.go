@@ -0,0 +1,73 @@
+package sri
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDeployed(t *testing.T) {
+	srv := httptest.NewServer(http.StripPrefix("/static/", http.FileServer(http.Dir("test"))))
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir(".", "sri")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir. %q", err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string][]byte{
+		"test.js":     readTestFile(t, "test/test.js"),
+		"test.min.js": []byte("changed since deploy"),
+		"new.js":      []byte("not yet deployed"),
+	}
+
+	for name, b := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), b, 0644); err != nil {
+			t.Fatalf("Unable to write test file. %q", err)
+		}
+	}
+
+	g := &Generator{Hash: SHA256, URLs: &URLMap{BaseURL: srv.URL + "/static/"}}
+	results, err := g.Deployed(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("Unexpected error from Deployed call. %q", err)
+	}
+
+	exp := map[string]string{
+		"new.js":      DeployedNotFound,
+		"test.js":     DeployedMatch,
+		"test.min.js": DeployedMismatch,
+	}
+
+	if len(results) != len(exp) {
+		t.Fatalf("Expected %d results. Got %d (%+v)", len(exp), len(results), results)
+	}
+
+	for _, r := range results {
+		if exp[r.File] != r.Status {
+			t.Fatalf("Expected %s to be %s. Got %+v", r.File, exp[r.File], r)
+		}
+
+		if r.URL != srv.URL+"/static/"+r.File {
+			t.Fatalf("Expected %s to be deployed at %s. Got %s", r.File, srv.URL+"/static/"+r.File, r.URL)
+		}
+	}
+
+	if _, err := (&Generator{Hash: SHA256}).Deployed(context.Background(), dir); err == nil {
+		t.Fatalf("Expected an error checking deployed files without URLs")
+	}
+
+	if _, err := (&Generator{URLs: g.URLs}).Deployed(context.Background(), dir); err == nil {
+		t.Fatalf("Expected an error checking deployed files without a hashing algorithm")
+	}
+
+	var f DeployedFile
+	if f.compare(nil, nil, nil, nil); f.Status != DeployedError {
+		t.Fatalf("Expected files without digests not to match. Got %+v", f)
+	}
+}
@@ -0,0 +1,605 @@
+package sri
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultFingerprintLength is the number of hex characters of a digest included in fingerprinted file names.
+const DefaultFingerprintLength = 8
+
+// FingerprintOptions configures Fingerprint.
+type FingerprintOptions struct {
+	// Length is the number of hex characters of the digest included in names. DefaultFingerprintLength if zero.
+	Length int
+
+	// Rename removes each original file once its fingerprinted copy is written, rather than keeping both.
+	Rename bool
+
+	// DryRun works out the fingerprinted names without writing or removing any files.
+	DryRun bool
+}
+
+// Fingerprint is the fingerprinted name of a file, along with the integrity of its (rewritten) content.
+type Fingerprint struct {
+	Path      string `json:"path"`
+	Integrity string `json:"integrity"`
+}
+
+// FingerprintManifest maps the slash separated path of each fingerprinted file, relative to the directory it was
+// found in, to its Fingerprint.
+type FingerprintManifest map[string]Fingerprint
+
+// fingerprintRewriteExts are the extensions of files whose references to other files are rewritten.
+var fingerprintRewriteExts = map[string]bool{
+	".html":        true,
+	".htm":         true,
+	".css":         true,
+	".js":          true,
+	".mjs":         true,
+	".json":        true,
+	".webmanifest": true,
+}
+
+// fingerprintKeepExts are the extensions of files that are rewritten in place rather than fingerprinted, as pages
+// and manifests are loaded by their well known names.
+var fingerprintKeepExts = map[string]bool{
+	".html":        true,
+	".htm":         true,
+	".json":        true,
+	".webmanifest": true,
+}
+
+// Fingerprint copies each file of dir to a name including a short digest of its content (e.g app.3f9a1c2b.js) for
+// cache busting, returning the mapping of original to fingerprinted names. References to fingerprinted files from
+// HTML, CSS, JS and JSON manifest files are rewritten, with root-relative references resolved against dir, before
+// those files are themselves hashed; so every digest matches the content that is served. HTML and JSON files keep
+// their names. The paths of the files rewritten in place are also returned.
+func (g *Generator) Fingerprint(dir string, opts FingerprintOptions) (FingerprintManifest, []string, error) {
+	if opts.Length <= 0 {
+		opts.Length = DefaultFingerprintLength
+	}
+
+	files, err := g.walk(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f := &fingerprinter{
+		g:       g,
+		opts:    opts,
+		files:   make(map[string]walkedFile),
+		content: make(map[string][]byte),
+		refs:    make(map[string][]string),
+		state:   make(map[string]int),
+		result:  FingerprintManifest{},
+	}
+
+	for _, wf := range files {
+		if fingerprintRewriteExts[strings.ToLower(path.Ext(wf.Rel))] {
+			b, err := ioutil.ReadFile(wf.Path)
+			if err != nil {
+				return nil, nil, err
+			}
+			f.content[wf.Rel] = b
+		}
+
+		f.files[wf.Rel] = wf
+	}
+
+	if err := f.skipFingerprinted(); err != nil {
+		return nil, nil, err
+	}
+
+	for rel, b := range f.content {
+		f.refs[rel] = f.references(rel, b)
+	}
+
+	rewritten := []string{}
+	for _, wf := range files {
+		if _, ok := f.files[wf.Rel]; !ok {
+			continue
+		}
+
+		if fingerprintKeepExts[strings.ToLower(path.Ext(wf.Rel))] {
+			changed, err := f.rewriteInPlace(wf)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if changed {
+				rewritten = append(rewritten, wf.Path)
+			}
+			continue
+		}
+
+		if err := f.fingerprint(wf.Rel); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return f.result, rewritten, nil
+}
+
+type fingerprinter struct {
+	g       *Generator
+	opts    FingerprintOptions
+	files   map[string]walkedFile
+	content map[string][]byte
+	refs    map[string][]string
+	state   map[string]int
+	result  FingerprintManifest
+}
+
+const (
+	fingerprintVisiting = iota + 1
+	fingerprintDone
+)
+
+// fingerprint writes the fingerprinted copy of the file at rel, after fingerprinting every file it references so
+// its references can be rewritten first.
+func (f *fingerprinter) fingerprint(rel string) error {
+	switch f.state[rel] {
+	case fingerprintDone:
+		return nil
+	case fingerprintVisiting:
+		return fmt.Errorf("Unable to fingerprint %s. Its references lead back to itself", rel)
+	}
+	f.state[rel] = fingerprintVisiting
+
+	for _, ref := range f.refs[rel] {
+		if fingerprintKeepExts[strings.ToLower(path.Ext(ref))] {
+			continue
+		}
+
+		if err := f.fingerprint(ref); err != nil {
+			return err
+		}
+	}
+
+	wf := f.files[rel]
+	b, ok := f.content[rel]
+	if ok {
+		b = f.rewrite(rel, b)
+	} else {
+		var err error
+		if b, err = ioutil.ReadFile(wf.Path); err != nil {
+			return err
+		}
+	}
+
+	digests, _, err := f.g.digests(bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+
+	short, err := shortDigest(digests[0], f.opts.Length)
+	if err != nil {
+		return err
+	}
+
+	name := fingerprintName(path.Base(rel), short)
+	f.result[rel] = Fingerprint{Path: path.Join(path.Dir(rel), name), Integrity: strings.Join(digests, " ")}
+	f.state[rel] = fingerprintDone
+
+	if f.opts.DryRun {
+		return nil
+	}
+
+	info, err := os.Stat(wf.Path)
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(filepath.Dir(wf.Path), name), b, info.Mode().Perm()); err != nil {
+		return err
+	}
+
+	if f.opts.Rename {
+		return os.Remove(wf.Path)
+	}
+
+	return nil
+}
+
+// rewriteInPlace rewrites the references of a file which keeps its name, once every file it references has been
+// fingerprinted, reporting whether the file changed.
+func (f *fingerprinter) rewriteInPlace(wf walkedFile) (bool, error) {
+	for _, ref := range f.refs[wf.Rel] {
+		if fingerprintKeepExts[strings.ToLower(path.Ext(ref))] {
+			continue
+		}
+
+		if err := f.fingerprint(ref); err != nil {
+			return false, err
+		}
+	}
+
+	b := f.content[wf.Rel]
+	rewritten := f.rewrite(wf.Rel, b)
+	if bytes.Equal(b, rewritten) {
+		return false, nil
+	}
+
+	if f.opts.DryRun {
+		return true, nil
+	}
+
+	info, err := os.Stat(wf.Path)
+	if err != nil {
+		return false, err
+	}
+
+	return true, ioutil.WriteFile(wf.Path, rewritten, info.Mode().Perm())
+}
+
+// skipFingerprinted drops files whose names already include a fingerprint of their content, such as the copies left
+// by an earlier run, so they aren't fingerprinted again.
+func (f *fingerprinter) skipFingerprinted() error {
+	for rel, wf := range f.files {
+		b, ok := f.content[rel]
+		if !ok {
+			var err error
+			if b, err = ioutil.ReadFile(wf.Path); err != nil {
+				return err
+			}
+		}
+
+		digests, _, err := f.g.digests(bytes.NewReader(b))
+		if err != nil {
+			return err
+		}
+
+		short, err := shortDigest(digests[0], f.opts.Length)
+		if err != nil {
+			return err
+		}
+
+		base := path.Base(rel)
+		if strings.Contains(base, "."+short+".") || strings.HasSuffix(base, "."+short) {
+			delete(f.files, rel)
+			delete(f.content, rel)
+		}
+	}
+
+	return nil
+}
+
+// references returns the files referenced by the content of the file at rel.
+func (f *fingerprinter) references(rel string, b []byte) []string {
+	refs := []string{}
+	seen := make(map[string]bool)
+
+	for _, tok := range referenceTokens(rel, b) {
+		target, ok := f.resolve(rel, string(b[tok[0]:tok[1]]))
+		if ok && target != rel && !seen[target] {
+			seen[target] = true
+			refs = append(refs, target)
+		}
+	}
+
+	return refs
+}
+
+// rewrite replaces every reference to a fingerprinted file in the content of the file at rel.
+func (f *fingerprinter) rewrite(rel string, b []byte) []byte {
+	var out bytes.Buffer
+	last := 0
+
+	for _, tok := range referenceTokens(rel, b) {
+		ref := string(b[tok[0]:tok[1]])
+		target, ok := f.resolve(rel, ref)
+		if !ok {
+			continue
+		}
+
+		fp, ok := f.result[target]
+		if !ok {
+			continue
+		}
+
+		out.Write(b[last:tok[0]])
+		out.WriteString(ref[:strings.LastIndex(ref, "/")+1] + path.Base(fp.Path))
+		last = tok[1]
+	}
+
+	out.Write(b[last:])
+	return out.Bytes()
+}
+
+// resolve resolves a reference found in the file at rel to the path of another file of the directory.
+func (f *fingerprinter) resolve(rel, ref string) (string, bool) {
+	if strings.Contains(ref, "://") || strings.HasPrefix(ref, "//") || strings.HasPrefix(ref, "data:") {
+		return "", false
+	}
+
+	var target string
+	if strings.HasPrefix(ref, "/") {
+		target = path.Clean(strings.TrimPrefix(ref, "/"))
+	} else {
+		target = path.Join(path.Dir(rel), ref)
+	}
+
+	if _, ok := f.files[target]; !ok {
+		return "", false
+	}
+
+	return target, true
+}
+
+// referenceTokens returns the offsets of every reference to another file in the content of the file at rel; the
+// src, href and srcset attributes of HTML (and the content of its inline styles and scripts), url() and @import
+// in CSS, module specifiers in JS and string values in JSON. Prose, comments and any other text are never
+// references.
+func referenceTokens(rel string, b []byte) [][2]int {
+	switch strings.ToLower(path.Ext(rel)) {
+	case ".html", ".htm":
+		return htmlReferenceTokens(b)
+	case ".css":
+		return cssReferenceTokens(b, 0, len(b))
+	case ".js", ".mjs":
+		return jsReferenceTokens(b, 0, len(b))
+	default:
+		return jsonReferenceTokens(b)
+	}
+}
+
+func htmlReferenceTokens(doc []byte) [][2]int {
+	tokens := [][2]int{}
+	for _, tag := range scanTags(doc) {
+		for _, attr := range tag.Attrs {
+			switch attr.Name {
+			case "src", "href":
+				tokens = appendReferenceToken(tokens, doc, attr.ValueStart, attr.ValueEnd)
+			case "srcset":
+				tokens = append(tokens, srcsetReferenceTokens(doc, attr.ValueStart, attr.ValueEnd)...)
+			}
+		}
+
+		switch tag.Name {
+		case "style":
+			tokens = append(tokens, cssReferenceTokens(doc, tag.TextStart, tag.TextEnd)...)
+		case "script":
+			tokens = append(tokens, jsReferenceTokens(doc, tag.TextStart, tag.TextEnd)...)
+		}
+	}
+
+	return tokens
+}
+
+// srcsetReferenceTokens returns the URL of each image candidate of a srcset attribute value, e.g
+// 'img/a.png 1x, img/b.png 2x'.
+func srcsetReferenceTokens(doc []byte, start, end int) [][2]int {
+	tokens := [][2]int{}
+	for i := start; i < end; {
+		for i < end && (isSpace(doc[i]) || doc[i] == ',') {
+			i++
+		}
+
+		urlStart := i
+		for i < end && !isSpace(doc[i]) {
+			i++
+		}
+
+		urlEnd := i
+		for urlEnd > urlStart && doc[urlEnd-1] == ',' {
+			urlEnd--
+		}
+		tokens = appendReferenceToken(tokens, doc, urlStart, urlEnd)
+
+		// Skip the descriptors of the candidate, unless its URL ended the candidate with a comma
+		if urlEnd == i {
+			for i < end && doc[i] != ',' {
+				i++
+			}
+		}
+	}
+
+	return tokens
+}
+
+// cssReferenceTokens returns the references of url() and @import rules between start and end, skipping comments.
+func cssReferenceTokens(b []byte, start, end int) [][2]int {
+	tokens := [][2]int{}
+	for i := start; i < end; i++ {
+		switch {
+		case bytes.HasPrefix(b[i:end], []byte("/*")):
+			i = skipPast(b[:end], i+2, "*/")
+		case b[i] == '"' || b[i] == '\'':
+			i = skipString(b, i, end)
+		case hasPrefixFold(b[i:end], "url(") && (i == start || !isCSSNameChar(b[i-1])):
+			i += len("url(")
+			for i < end && isSpace(b[i]) {
+				i++
+			}
+
+			if i < end && (b[i] == '"' || b[i] == '\'') {
+				closing := skipString(b, i, end)
+				tokens = appendReferenceToken(tokens, b, i+1, closing)
+				i = closing
+				continue
+			}
+
+			valueStart := i
+			for i < end && b[i] != ')' {
+				i++
+			}
+			tokens = appendReferenceToken(tokens, b, valueStart, i)
+		case hasPrefixFold(b[i:end], "@import"):
+			i += len("@import")
+			for i < end && isSpace(b[i]) {
+				i++
+			}
+
+			if i < end && (b[i] == '"' || b[i] == '\'') {
+				closing := skipString(b, i, end)
+				tokens = appendReferenceToken(tokens, b, i+1, closing)
+				i = closing
+				continue
+			}
+			i--
+		}
+	}
+
+	return tokens
+}
+
+// jsReferenceTokens returns every module specifier between start and end; the strings of import and export ... from
+// declarations, bare imports and dynamic import() calls. Other string literals are left alone, as any of them could
+// be a label or a key that happens to share the name of a file.
+func jsReferenceTokens(b []byte, start, end int) [][2]int {
+	tokens := [][2]int{}
+	for i := start; i < end; i++ {
+		switch {
+		case bytes.HasPrefix(b[i:end], []byte("//")):
+			for i < end && b[i] != '\n' {
+				i++
+			}
+		case bytes.HasPrefix(b[i:end], []byte("/*")):
+			i = skipPast(b[:end], i+2, "*/")
+		case b[i] == '"' || b[i] == '\'' || b[i] == '`':
+			closing := skipString(b, i, end)
+			// Template literals with substitutions are built at runtime, so can't be rewritten
+			if isModuleSpecifier(b, start, i) && (b[i] != '`' || !bytes.Contains(b[i:closing], []byte("${"))) {
+				tokens = appendReferenceToken(tokens, b, i+1, closing)
+			}
+			i = closing
+		}
+	}
+
+	return tokens
+}
+
+// isModuleSpecifier reports whether the string starting at b[i] is a module specifier, following 'from', a bare
+// 'import' or the opening parenthesis of 'import('.
+func isModuleSpecifier(b []byte, start, i int) bool {
+	i = skipSpaceBack(b, start, i)
+	if i > start && b[i-1] == '(' {
+		i = skipSpaceBack(b, start, i-1)
+		return precedingWord(b, start, i) == "import"
+	}
+
+	word := precedingWord(b, start, i)
+	return word == "from" || word == "import"
+}
+
+// skipSpaceBack returns the offset following the last non-space byte of b between start and i.
+func skipSpaceBack(b []byte, start, i int) int {
+	for i > start && isSpace(b[i-1]) {
+		i--
+	}
+
+	return i
+}
+
+// precedingWord returns the identifier ending at b[i], or "" if there is none or it is a property, e.g 'x.import'.
+func precedingWord(b []byte, start, i int) string {
+	j := i
+	for j > start && isJSNameChar(b[j-1]) {
+		j--
+	}
+
+	if j > start && b[j-1] == '.' {
+		return ""
+	}
+
+	return string(b[j:i])
+}
+
+// jsonReferenceTokens returns the content of every string of a JSON document, e.g the src of a manifest's icons.
+func jsonReferenceTokens(b []byte) [][2]int {
+	tokens := [][2]int{}
+	for i := 0; i < len(b); i++ {
+		if b[i] == '"' {
+			closing := skipString(b, i, len(b))
+			tokens = appendReferenceToken(tokens, b, i+1, closing)
+			i = closing
+		}
+	}
+
+	return tokens
+}
+
+// appendReferenceToken appends the reference between start and end, trimmed of whitespace and stopping at any query
+// or fragment, if it isn't empty.
+func appendReferenceToken(tokens [][2]int, b []byte, start, end int) [][2]int {
+	for start < end && isSpace(b[start]) {
+		start++
+	}
+
+	for i := start; i < end; i++ {
+		if b[i] == '?' || b[i] == '#' {
+			end = i
+			break
+		}
+	}
+
+	for end > start && isSpace(b[end-1]) {
+		end--
+	}
+
+	if start == end {
+		return tokens
+	}
+
+	return append(tokens, [2]int{start, end})
+}
+
+// skipString returns the offset of the quote closing the string starting at b[start], or end if it isn't closed.
+func skipString(b []byte, start, end int) int {
+	quote := b[start]
+	for i := start + 1; i < end; i++ {
+		switch b[i] {
+		case '\\':
+			i++
+		case quote:
+			return i
+		case '\n':
+			if quote != '`' {
+				return i
+			}
+		}
+	}
+
+	return end
+}
+
+func hasPrefixFold(b []byte, prefix string) bool {
+	return len(b) >= len(prefix) && bytes.EqualFold(b[:len(prefix)], []byte(prefix))
+}
+
+func isCSSNameChar(c byte) bool {
+	return isASCIILetter(c) || c >= '0' && c <= '9' || c == '-' || c == '_'
+}
+
+func isJSNameChar(c byte) bool {
+	return isASCIILetter(c) || c >= '0' && c <= '9' || c == '_' || c == '$'
+}
+
+// shortDigest returns the first length hex characters of a digest such as 'sha256-...'.
+func shortDigest(digest string, length int) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(digest[strings.Index(digest, "-")+1:])
+	if err != nil {
+		return "", err
+	}
+
+	h := hex.EncodeToString(raw)
+	if length > len(h) {
+		length = len(h)
+	}
+
+	return h[:length], nil
+}
+
+// fingerprintName inserts a short digest before the extension of a file name, e.g app.js becomes app.3f9a1c2b.js.
+func fingerprintName(name, short string) string {
+	ext := path.Ext(name)
+	return strings.TrimSuffix(name, ext) + "." + short + ext
+}
@@ -0,0 +1,119 @@
+package sri
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "sri")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir. %q", err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"index.html":   `<link rel="stylesheet" href="/css/app.css?v=1"><script src="js/app.js"></script><p>Edit js/app.js to change the page</p><img srcset="img/logo.png 2x">`,
+		"css/app.css":  `/* ../img/logo.png is the logo */ body { background: url(../img/logo.png) }`,
+		"img/logo.png": `png`,
+		"js/app.js":    "// dep.js is loaded first\nimport dep from \"./dep.js\"; const cdn = \"https://cdn.com/js/dep.js\", label = \"dep.js\";",
+		"js/dep.js":    `export default "app.js";`,
+	}
+
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(p), 0755)
+		if err := ioutil.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatalf("Unable to write test file. %q", err)
+		}
+	}
+
+	g := &Generator{Hash: SHA256}
+	m, rewritten, err := g.Fingerprint(dir, FingerprintOptions{Rename: true})
+	if err != nil {
+		t.Fatalf("Unexpected error from Fingerprint call. %q", err)
+	}
+
+	if len(m) != 4 || len(rewritten) != 1 {
+		t.Fatalf("Expected 4 fingerprinted files and 1 rewritten page. Got %+v and %q", m, rewritten)
+	}
+
+	base := func(rel string) string { return filepath.Base(m[rel].Path) }
+	exp := map[string]string{
+		"index.html":            `<link rel="stylesheet" href="/css/` + base("css/app.css") + `?v=1"><script src="js/` + base("js/app.js") + `"></script><p>Edit js/app.js to change the page</p><img srcset="img/` + base("img/logo.png") + ` 2x">`,
+		m["css/app.css"].Path:   `/* ../img/logo.png is the logo */ body { background: url(../img/` + base("img/logo.png") + `) }`,
+		m["js/app.js"].Path:     "// dep.js is loaded first\nimport dep from \"./" + base("js/dep.js") + "\"; const cdn = \"https://cdn.com/js/dep.js\", label = \"dep.js\";",
+		m["js/dep.js"].Path:     `export default "app.js";`,
+		"img/logo.8f8cbb7d.png": `png`,
+	}
+
+	for name, content := range exp {
+		b, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatalf("Expected %s to exist. %q", name, err)
+		}
+
+		if string(b) != content {
+			t.Fatalf("Expected %s to contain %q. Got %q", name, content, b)
+		}
+	}
+
+	fis, err := g.File(filepath.Join(dir, filepath.FromSlash(m["js/app.js"].Path)))
+	if err != nil || fis[0].Digest != m["js/app.js"].Integrity {
+		t.Fatalf("Expected the integrity of js/app.js to match its rewritten content. Got %q", m["js/app.js"].Integrity)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "js", "app.js")); !os.IsNotExist(err) {
+		t.Fatalf("Expected js/app.js to be renamed")
+	}
+
+	m, _, err = g.Fingerprint(dir, FingerprintOptions{})
+	if err != nil || len(m) != 0 {
+		t.Fatalf("Expected fingerprinted files not to be fingerprinted again. Got %+v (%v)", m, err)
+	}
+}
+
+func TestReferenceTokens(t *testing.T) {
+	type testCase struct {
+		rel  string
+		doc  string
+		refs []string
+	}
+
+	tcs := []testCase{
+		{"index.html", `<p>See js/app.js</p><a href="js/app.js#top">`, []string{"js/app.js"}},
+		{"index.html", `<img srcset="a.png 1x, b.png 2x"><img srcset="c.png, d.png">`, []string{"a.png", "b.png", "c.png", "d.png"}},
+		{"index.html", `<style>/* a.png */ p { background: url('b.png') }</style><script>// c.js
+import "./d.js";</script>`, []string{"b.png", "./d.js"}},
+		{"app.css", `@import "base.css"; @import url(reset.css); p { content: "a.png" } /* url(b.png) */`, []string{"base.css", "reset.css"}},
+		{"app.js", "/* import 'a.js' */ // b.js\nimport c from 'c.js'; export * from \"d.js\"\nimport 'e.js'; import(`f.js`); import(`${dir}/g.js`); h.js", []string{"c.js", "d.js", "e.js", "f.js"}},
+		{"app.js", "const label = \"app.js\", icon = 'icon.png'; load(`a.js`); x.import('b.js')", []string{}},
+		{"manifest.json", `{"icons": [{"src": "icon.png?v=2"}]}`, []string{"icons", "src", "icon.png"}},
+	}
+
+	for _, tc := range tcs {
+		refs := []string{}
+		for _, tok := range referenceTokens(tc.rel, []byte(tc.doc)) {
+			refs = append(refs, tc.doc[tok[0]:tok[1]])
+		}
+
+		if !reflect.DeepEqual(refs, tc.refs) {
+			t.Fatalf("Expected the references of %s to be %q. Got %q", tc.doc, tc.refs, refs)
+		}
+	}
+}
+
+func TestFingerprintName(t *testing.T) {
+	for name, exp := range map[string]string{
+		"app.js":     "app.3f9a1c2b.js",
+		"app.min.js": "app.min.3f9a1c2b.js",
+		"LICENSE":    "LICENSE.3f9a1c2b",
+	} {
+		if got := fingerprintName(name, "3f9a1c2b"); got != exp {
+			t.Fatalf("Expected %s to be fingerprinted as %s. Got %s", name, exp, got)
+		}
+	}
+}
@@ -0,0 +1,593 @@
+package sri
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+const (
+	SHA256    = "sha256"
+	SHA384    = "sha384"
+	SHA512    = "sha512"
+	AllHashes = "all"
+
+	// StdinTarget is the target read from the Stdin of a Generator.
+	StdinTarget = "-"
+)
+
+var (
+	hashes = map[string]func() hash.Hash{
+		SHA256:    func() hash.Hash { return sha256.New() },
+		SHA384:    func() hash.Hash { return sha512.New384() },
+		SHA512:    func() hash.Hash { return sha512.New() },
+		AllHashes: func() hash.Hash { return nil },
+	}
+
+	// DefaultClient is the HTTP client used to download remote targets when a Generator has no Client set. Its
+	// timeout covers reading the whole response, so is long enough for large bundles on slow connections; use
+	// NewClient for a different timeout.
+	DefaultClient = NewClient(time.Second*30, 10)
+)
+
+// Generator produces integrities for URLs, files and directories using the configured hashing algorithm.
+type Generator struct {
+	// Hash is one of 'sha256', 'sha384', 'sha512' or 'all', or a comma separated list of algorithms.
+	Hash string
+
+	// Combine produces tags carrying the digests of every algorithm in a single integrity attribute.
+	Combine bool
+
+	// Tags renders the tag of each Integrity. Tags with the default TagOptions are rendered if nil.
+	Tags *TagRenderer
+
+	// Preload also renders a preload (or modulepreload) tag and Link header value for each Integrity.
+	Preload bool
+
+	// TagTemplate, if set, renders the tag of each Integrity in place of Tags. It is executed with a TemplateFile.
+	TagTemplate *template.Template
+
+	// Include and Exclude are glob patterns matched against the paths of files relative to directory targets. Only
+	// files matching an include pattern (if there are any) and no exclude pattern are hashed.
+	Include []string
+	Exclude []string
+
+	// Symlinks is the policy for symlinks found in directory targets; SymlinksSkip (the default), SymlinksFollow or
+	// SymlinksError.
+	Symlinks string
+
+	// Concurrency is the maximum number of files hashed at once. The number of CPUs is used if zero.
+	Concurrency int
+
+	// KeepGoing continues hashing the remaining targets after a failure, rather than cancelling them. The
+	// integrities of every successful target are returned alongside the Errors of those that failed.
+	KeepGoing bool
+
+	// Client is used to download remote targets. DefaultClient is used if nil.
+	Client *http.Client
+
+	// Headers are added to every request made to download remote targets, except for an Authorization header which
+	// is only sent to AuthHosts.
+	Headers http.Header
+
+	// AuthHosts are the hosts (e.g 'cdn.example.com' or 'localhost:8080') credentials are sent to. The Authorization
+	// header is left out of requests to any other host, such as third-party URLs found in pages, and is dropped from
+	// redirects leaving AuthHosts.
+	AuthHosts []string
+
+	// Retries is the number of times a download is retried after a timeout or a 5xx response. The first retry waits
+	// for RetryBackoff, with the wait doubling for each subsequent retry.
+	Retries      int
+	RetryBackoff time.Duration
+
+	// ExpectContentType is checked against the Content-Type of every download. It may be a MIME type, 'js' or 'css'
+	// for any of their MIME types, or ContentTypeAuto to choose between 'js' and 'css' by the extension of the URL.
+	// No check is made if empty.
+	ExpectContentType string
+
+	// MinSize is the minimum number of bytes each download must contain.
+	MinSize int64
+
+	// Origin, if set, is sent with every download so the CORS headers of the response can be checked against the
+	// CrossOrigin mode the resource will be loaded with; CrossOriginAnonymous (the default),
+	// CrossOriginUseCredentials or CrossOriginNone. Problems are recorded in the Response of each Integrity, or are
+	// errors if StrictCORS is set.
+	Origin      string
+	CrossOrigin string
+	StrictCORS  bool
+
+	// Stdin is read in place of the target StdinTarget, with the resulting integrities named and tagged as
+	// StdinName ('-' if empty). os.Stdin is used if nil.
+	Stdin     io.Reader
+	StdinName string
+
+	// URLs, if set, maps local files to the public URLs they are served from. Their public URL is used as the Source
+	// (and so in the tags) of their integrities, with the local path kept as their Path, while the digests are still
+	// produced from the local files.
+	URLs *URLMap
+
+	// Environments maps names, e.g 'staging' and 'production', to the public URLs of files in each environment. The
+	// tags of each environment are recorded in the Environments of each Integrity.
+	Environments map[string]*URLMap
+
+	// Expect, if set, is an integrity attribute value every file must match, as a browser would check it. Files
+	// which don't match fail to generate.
+	Expect string
+
+	// Type, if set to one of the Source types other than SourceAuto, is the type every target must be. Targets are
+	// otherwise classified by their scheme and what they are on disk. Setting SourceFile hashes archives as a whole.
+	Type string
+}
+
+// NewGenerator returns a Generator for the given hashing algorithm, or an error if the algorithm is not supported.
+func NewGenerator(hashName string) (*Generator, error) {
+	if err := ValidateHash(hashName); err != nil {
+		return nil, err
+	}
+
+	return &Generator{Hash: hashName}, nil
+}
+
+// Generate produces the integrities of every target, sorted by file name. Targets may be URLs, files or directories.
+func (g *Generator) Generate(targets []string) ([]Integrity, error) {
+	return g.GenerateContext(context.Background(), targets)
+}
+
+// GenerateContext produces the integrities of every target, sorted by file name, hashing at most Concurrency files
+// at once. Unless KeepGoing is set, the first failure cancels any outstanding work. The errors of every failed
+// target are returned together as Errors.
+func (g *Generator) GenerateContext(ctx context.Context, targets []string) ([]Integrity, error) {
+	var errs Errors
+	var jobs []job
+	stdin := false
+	for _, target := range targets {
+		if target == StdinTarget {
+			if stdin {
+				return nil, fmt.Errorf("Stdin can only be used as a target once")
+			}
+			stdin = true
+		}
+
+		tjobs, err := g.jobs(target)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		jobs = append(jobs, tjobs...)
+	}
+
+	if len(errs) > 0 && !g.KeepGoing {
+		return nil, errs
+	}
+
+	combined, runErrs := g.run(ctx, jobs)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	errs = append(errs, runErrs...)
+
+	if len(errs) > 0 && !g.KeepGoing {
+		return nil, errs
+	}
+
+	if len(combined) == 0 {
+		if len(errs) > 0 {
+			return nil, errs
+		}
+
+		return nil, fmt.Errorf("No file integrities generated from targets '%q'", targets)
+	}
+
+	combined, err := dedupe(combined)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Stable(combined)
+
+	if len(errs) > 0 {
+		return combined, errs
+	}
+
+	return combined, nil
+}
+
+// job is a single URL, file, archive, data: URI or stdin to be hashed, with kind being one of the Source types.
+// Name, if set, replaces the file name of the resulting integrities. Member, if set, limits an archive to that one
+// file.
+type job struct {
+	target string
+	name   string
+	member string
+	kind   string
+}
+
+// jobs classifies the target, expanding directories into a job for each of their files.
+func (g *Generator) jobs(target string) ([]job, error) {
+	kind, location, member, err := g.classify(target)
+	if err != nil {
+		return nil, err
+	}
+
+	switch kind {
+	case SourceStdin:
+		return []job{{target: location, name: g.stdinName(), kind: kind}}, nil
+	case SourceDir:
+	default:
+		return []job{{target: location, member: member, kind: kind}}, nil
+	}
+
+	files, err := g.walk(location)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]job, len(files))
+	for i, wf := range files {
+		jobs[i] = job{target: wf.Path, name: wf.Rel, kind: SourceFile}
+	}
+
+	return jobs, nil
+}
+
+// run hashes the jobs with a pool of Concurrency workers.
+func (g *Generator) run(ctx context.Context, jobs []job) (Integrities, Errors) {
+	results, jobErrs := g.runJobs(ctx, jobs)
+
+	var errs Errors
+	combined := Integrities{}
+	for i := range jobs {
+		if jobErrs[i] != nil {
+			errs = append(errs, jobErrs[i])
+		}
+		combined = append(combined, results[i]...)
+	}
+
+	return combined, errs
+}
+
+// runJobs hashes the jobs with a pool of Concurrency workers, returning the integrities and error of each job by its
+// index. Unless KeepGoing is set, the first failure cancels any outstanding jobs, which are left without either.
+func (g *Generator) runJobs(ctx context.Context, jobs []job) ([][]Integrity, []error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	workers := g.Concurrency
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(jobs) {
+		workers = len(jobs)
+	}
+
+	indexes := make(chan int)
+	go func() {
+		defer close(indexes)
+		for i := range jobs {
+			select {
+			case indexes <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	results := make([][]Integrity, len(jobs))
+	errs := make([]error, len(jobs))
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				fis, err := g.hash(ctx, jobs[i])
+				if err != nil {
+					// Work abandoned because of an earlier failure isn't worth reporting
+					if err != ctx.Err() {
+						errs[i] = err
+					}
+
+					if !g.KeepGoing {
+						cancel()
+					}
+				}
+				results[i] = fis
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return results, errs
+}
+
+func (g *Generator) hash(ctx context.Context, j job) ([]Integrity, error) {
+	var fis []Integrity
+	var err error
+	switch j.kind {
+	case SourceURL:
+		fis, err = g.download(ctx, j.target)
+	case SourceStdin:
+		fis, err = g.stdin(ctx)
+	case SourceArchive:
+		fis, err = g.archive(ctx, j.target, j.member)
+	case SourceData:
+		fis, err = g.data(ctx, j.target)
+	default:
+		fis, err = g.file(ctx, j.target)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if j.name != "" {
+		for i := range fis {
+			fis[i].FileName = j.name
+		}
+
+		// Tags are rendered again as templates may use the file name
+		if g.TagTemplate != nil {
+			if err := g.renderTags(fis); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if j.kind != SourceURL && j.kind != SourceData && (g.URLs != nil || len(g.Environments) > 0) {
+		if err := g.mapURLs(fis, urlPath(j)); err != nil {
+			return nil, err
+		}
+	}
+
+	return fis, nil
+}
+
+// urlPath returns the path a job's files are joined with a base URL by; the path of files targeted directly relative
+// to the working directory, or "" to use the file name of files found in directories, archives and stdin.
+func urlPath(j job) string {
+	if j.kind != SourceFile || j.name != "" {
+		return ""
+	}
+
+	rel := j.target
+	if filepath.IsAbs(rel) {
+		if wd, err := os.Getwd(); err == nil {
+			if r, err := filepath.Rel(wd, rel); err == nil {
+				rel = r
+			}
+		}
+	}
+
+	rel = filepath.ToSlash(filepath.Clean(rel))
+	if rel == ".." || strings.HasPrefix(rel, "../") || path.IsAbs(rel) {
+		return path.Base(rel)
+	}
+
+	return rel
+}
+
+// dedupe drops integrities that were produced more than once from the same source, and returns an error if two
+// different sources would share the same file name. Local names and sources are compared once cleaned, so
+// 'test/test.js' and './test/test.js' are the same file.
+func dedupe(fis Integrities) (Integrities, error) {
+	type key struct{ name, algo string }
+	sources := make(map[key]string)
+
+	result := Integrities{}
+	for _, fi := range fis {
+		k := key{cleanSource(fi.FileName), digestAlgorithm(fi.Digest)}
+		if source, ok := sources[k]; ok {
+			if cleanSource(source) != cleanSource(fi.localSource()) {
+				return nil, fmt.Errorf("Both %s and %s would be identified as '%s'", source, fi.localSource(), fi.FileName)
+			}
+			continue
+		}
+
+		sources[k] = fi.localSource()
+		result = append(result, fi)
+	}
+
+	return result, nil
+}
+
+// cleanSource returns the shortest equivalent of a local path, leaving URLs and data: URIs untouched.
+func cleanSource(source string) string {
+	if isRemote(source) || isDataURI(source) {
+		return source
+	}
+
+	return filepath.Clean(source)
+}
+
+// Download fetches the target URL and produces the integrities of the response body.
+func (g *Generator) Download(target string) ([]Integrity, error) {
+	return g.download(context.Background(), target)
+}
+
+func (g *Generator) download(ctx context.Context, target string) ([]Integrity, error) {
+	resp, err := g.get(ctx, target)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	} else if se, ok := err.(*StatusError); ok {
+		return nil, se
+	} else if err != nil {
+		return nil, fmt.Errorf("Failure downloading script from %s. %s", target, err)
+	}
+	defer resp.Body.Close()
+
+	if err := g.checkContentType(target, resp); err != nil {
+		return nil, fmt.Errorf("Failure downloading script from %s. %s", target, err)
+	}
+
+	corsErr := g.checkCORS(target, resp)
+	if corsErr != nil && g.StrictCORS {
+		return nil, fmt.Errorf("Failure downloading script from %s. %s", target, corsErr)
+	}
+
+	body := &countingReader{r: resp.Body}
+	fis, err := g.Integrities(target, body)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	} else if err != nil {
+		return nil, err
+	}
+
+	if body.n < g.MinSize {
+		return nil, fmt.Errorf("Failure downloading script from %s. Received %d bytes, expected at least %d", target, body.n, g.MinSize)
+	}
+
+	r := newResponse(resp, body.n)
+	if corsErr != nil {
+		r.CORSError = corsErr.Error()
+	}
+	for i := range fis {
+		fis[i].Response = r
+	}
+
+	return fis, nil
+}
+
+// File produces the integrities of a single local file.
+func (g *Generator) File(target string) ([]Integrity, error) {
+	return g.file(context.Background(), target)
+}
+
+func (g *Generator) file(ctx context.Context, target string) ([]Integrity, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(target)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return g.Integrities(target, &contextReader{ctx: ctx, r: f})
+}
+
+func (g *Generator) stdin(ctx context.Context) ([]Integrity, error) {
+	r := g.Stdin
+	if r == nil {
+		r = os.Stdin
+	}
+
+	fis, err := g.Integrities(g.stdinName(), &contextReader{ctx: ctx, r: r})
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	} else if err != nil {
+		return nil, fmt.Errorf("Unable to read stdin. %s", err)
+	}
+
+	return fis, nil
+}
+
+func (g *Generator) stdinName() string {
+	if g.StdinName != "" {
+		return g.StdinName
+	}
+
+	return StdinTarget
+}
+
+// Dir recursively produces the integrities of the files in the target directory, named by their path relative to
+// the directory.
+func (g *Generator) Dir(target string) ([]Integrity, error) {
+	files, err := g.walk(target)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]job, len(files))
+	for i, wf := range files {
+		jobs[i] = job{target: wf.Path, name: wf.Rel, kind: SourceFile}
+	}
+
+	fis, errs := g.run(context.Background(), jobs)
+	if len(errs) > 0 {
+		return fis, errs
+	}
+
+	return fis, nil
+}
+
+// ValidateHash returns an error if hashName is not a supported hashing algorithm, or a comma separated list of them.
+func ValidateHash(hashName string) error {
+	for _, name := range strings.Split(hashName, ",") {
+		if _, ok := hashes[name]; !ok || name == AllHashes && hashName != AllHashes {
+			return fmt.Errorf("Invalid hashing algorithm '%s'. Expected one of 'sha256', 'sha384', 'sha512' or 'all'", hashName)
+		}
+	}
+
+	return nil
+}
+
+// hashNames returns the algorithms selected by hashName, in order of strength.
+func hashNames(hashName string) []string {
+	if hashName == AllHashes {
+		return []string{SHA256, SHA384, SHA512}
+	}
+
+	names := []string{}
+	for _, name := range []string{SHA256, SHA384, SHA512} {
+		for _, n := range strings.Split(hashName, ",") {
+			if n == name {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+
+	return names
+}
+
+func (g *Generator) tags() *TagRenderer {
+	if g.Tags != nil {
+		return g.Tags
+	}
+
+	return &TagRenderer{}
+}
+
+// Errors is the errors of every target which failed to generate.
+type Errors []error
+
+func (es Errors) Error() string {
+	msgs := make([]string, len(es))
+	for i, err := range es {
+		msgs[i] = err.Error()
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+// contextReader stops reading once its context is done, so large files can be abandoned part way through.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (cr *contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return cr.r.Read(p)
+}
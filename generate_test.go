@@ -0,0 +1,158 @@
+package sri
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var (
+	testWriteFileOutputPath = "test.json"
+)
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	os.Remove(testWriteFileOutputPath)
+	os.Exit(code)
+}
+
+func TestValidateHash(t *testing.T) {
+	for _, h := range []string{SHA256, SHA384, SHA512, AllHashes, "sha256,sha384", "sha512,sha256"} {
+		if err := ValidateHash(h); err != nil {
+			t.Fatalf("Expected %s to be a valid hash value", h)
+		}
+	}
+
+	for _, h := range []string{"not a real hash", "sha256,all", "sha256,", ""} {
+		if err := ValidateHash(h); err == nil {
+			t.Fatalf("Expected invalid hash value %q to produce an error", h)
+		}
+	}
+}
+
+func TestNewGenerator(t *testing.T) {
+	if _, err := NewGenerator("not a real hash"); err == nil {
+		t.Fatalf("Expected invalid hash value to produce an error")
+	}
+
+	g, err := NewGenerator(SHA384)
+	if err != nil {
+		t.Fatalf("Unexpected error from NewGenerator call. %q", err)
+	}
+
+	if g.Hash != SHA384 {
+		t.Fatalf("Expected generator to use %s. Got %s", SHA384, g.Hash)
+	}
+}
+
+func TestGenerateCollisions(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "sri")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir. %q", err)
+	}
+	defer os.RemoveAll(dir)
+
+	other := filepath.Join(dir, "test.js")
+	if err := ioutil.WriteFile(other, []byte("console.log('another test.js');"), 0644); err != nil {
+		t.Fatalf("Unable to write %s. %q", other, err)
+	}
+
+	g := &Generator{Hash: SHA256}
+	if _, err := g.Generate([]string{"test/test.js", other}); err == nil {
+		t.Fatalf("Expected an error generating two different files both named test.js")
+	}
+
+	fis, err := g.Generate([]string{"test/test.js", "test/test.js"})
+	if err != nil {
+		t.Fatalf("Unexpected error from Generate call with a repeated target. %q", err)
+	}
+
+	if len(fis) != 1 {
+		t.Fatalf("Expected a repeated target to produce 1 integrity. Got %d", len(fis))
+	}
+
+	fis, err = g.Generate([]string{"test/test.js", "./test/test.js", "test/../test/test.js"})
+	if err != nil {
+		t.Fatalf("Unexpected error from Generate call with equivalent paths. %q", err)
+	}
+
+	if len(fis) != 1 {
+		t.Fatalf("Expected equivalent paths to produce 1 integrity. Got %d", len(fis))
+	}
+}
+
+func TestGenerateKeepGoing(t *testing.T) {
+	targets := []string{"test/test.js", "test/not-real.js", "./test", "test/also-not-real.js"}
+
+	g := &Generator{Hash: SHA256, Concurrency: 2}
+	if fis, err := g.Generate(targets); err == nil || fis != nil {
+		t.Fatalf("Expected an error, and no integrities, from Generate call with missing targets")
+	}
+
+	g.KeepGoing = true
+	fis, err := g.Generate(targets)
+	errs, ok := err.(Errors)
+	if !ok || len(errs) != 2 {
+		t.Fatalf("Expected an error for each of the 2 missing targets. Got %q", err)
+	}
+
+	if len(fis) != 7 {
+		t.Fatalf("Expected integrities for each of the 7 files in ./test. Got %d", len(fis))
+	}
+}
+
+func TestGenerateContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	g := &Generator{Hash: SHA256, Concurrency: 1}
+	if _, err := g.GenerateContext(ctx, []string{"./test"}); err != context.Canceled {
+		t.Fatalf("Expected a cancelled context to produce %q. Got %q", context.Canceled, err)
+	}
+}
+
+func TestGenerateStdin(t *testing.T) {
+	js, err := ioutil.ReadFile("test/test.js")
+	if err != nil {
+		t.Fatalf("Unable to read test file. %q", err)
+	}
+
+	g := &Generator{Hash: SHA256, Stdin: bytes.NewReader(js), StdinName: "js/app.js"}
+	fis, err := g.Generate([]string{StdinTarget, "test/test.min.js"})
+	if err != nil {
+		t.Fatalf("Unexpected error from Generate call. %q", err)
+	}
+
+	if len(fis) != 2 {
+		t.Fatalf("Expected 2 integrities. Got %d", len(fis))
+	}
+
+	fi := fis[0]
+	if fi.FileName != "js/app.js" || fi.Source != "js/app.js" || fi.Digest != "sha256-jEUM4jWrIiMerWo9zYrx6XwQ5eI77uzuETBptBvPlRQ=" {
+		t.Fatalf("Expected stdin to be hashed and named js/app.js. Got %+v", fi)
+	}
+
+	if _, err := g.Generate([]string{StdinTarget, StdinTarget}); err == nil {
+		t.Fatalf("Expected an error using stdin as a target twice")
+	}
+}
+
+func TestGenerateExpect(t *testing.T) {
+	g := &Generator{Hash: SHA256, Expect: "sha384-zBTHeP/UZLYRhjvTi7r3Dx7MTCNf/ddGENI26AacmrgqzH8YOkA+EJ14MXpwD4wL"}
+
+	fis, err := g.Generate([]string{"test/test.js"})
+	if err != nil {
+		t.Fatalf("Unexpected error from Generate call. %q", err)
+	}
+
+	if len(fis) != 1 || fis[0].Digest != "sha256-jEUM4jWrIiMerWo9zYrx6XwQ5eI77uzuETBptBvPlRQ=" {
+		t.Fatalf("Expected only the sha256 digest of test.js to be generated. Got %+v", fis)
+	}
+
+	if _, err := g.Generate([]string{"test/test.min.js"}); err == nil {
+		t.Fatalf("Expected an error generating a file which doesn't match the expected integrity")
+	}
+}
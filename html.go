@@ -0,0 +1,240 @@
+package sri
+
+import (
+	"bytes"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// htmlTag is a start tag found in an HTML document. Start and End are the offsets of its opening '<' and
+// one past its closing '>'. TextStart and TextEnd are the offsets of the contents of raw text elements.
+type htmlTag struct {
+	Name               string
+	Start, End         int
+	TextStart, TextEnd int
+	Line               int
+	Attrs              []htmlAttr
+}
+
+// htmlAttr is an attribute of a start tag. Start and End are the offsets of the whole attribute, while
+// ValueStart and ValueEnd are the offsets of its unquoted value (both equal to End for a bare attribute).
+type htmlAttr struct {
+	Name                 string
+	Value                string
+	Start, End           int
+	ValueStart, ValueEnd int
+}
+
+// rawTextElements are elements whose content is not parsed as markup, so must be skipped when scanning for tags.
+var rawTextElements = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"textarea": true,
+	"title":    true,
+}
+
+// scanTags returns every start tag in doc, skipping comments, doctypes and the contents of raw text elements.
+func scanTags(doc []byte) []htmlTag {
+	tags := []htmlTag{}
+
+	for i := 0; i < len(doc); i++ {
+		if doc[i] != '<' || i+1 >= len(doc) {
+			continue
+		}
+
+		switch {
+		case bytes.HasPrefix(doc[i:], []byte("<!--")):
+			i = skipPast(doc, i+4, "-->")
+			continue
+		case doc[i+1] == '!' || doc[i+1] == '?' || doc[i+1] == '/':
+			i = skipPast(doc, i+1, ">")
+			continue
+		case !isASCIILetter(doc[i+1]):
+			continue
+		}
+
+		tag := scanTag(doc, i)
+		i = tag.End - 1
+
+		if rawTextElements[tag.Name] {
+			tag.TextStart, tag.TextEnd = tag.End, skipRawText(doc, tag.End, tag.Name)
+			i = tag.TextEnd - 1
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return tags
+}
+
+// scanTag parses the start tag beginning at doc[start].
+func scanTag(doc []byte, start int) htmlTag {
+	tag := htmlTag{Start: start, Line: bytes.Count(doc[:start], []byte("\n")) + 1}
+
+	i := start + 1
+	for i < len(doc) && !isSpace(doc[i]) && doc[i] != '>' && doc[i] != '/' {
+		i++
+	}
+	tag.Name = strings.ToLower(string(doc[start+1 : i]))
+
+	for i < len(doc) {
+		for i < len(doc) && (isSpace(doc[i]) || doc[i] == '/') {
+			i++
+		}
+
+		if i >= len(doc) {
+			break
+		}
+
+		if doc[i] == '>' {
+			tag.End = i + 1
+			return tag
+		}
+
+		attr := htmlAttr{Start: i}
+		for i < len(doc) && !isSpace(doc[i]) && doc[i] != '>' && doc[i] != '=' && !(doc[i] == '/' && i > attr.Start) {
+			i++
+		}
+		attr.Name = strings.ToLower(string(doc[attr.Start:i]))
+
+		j := i
+		for j < len(doc) && isSpace(doc[j]) {
+			j++
+		}
+
+		if j < len(doc) && doc[j] == '=' {
+			j++
+			for j < len(doc) && isSpace(doc[j]) {
+				j++
+			}
+
+			if j < len(doc) && (doc[j] == '"' || doc[j] == '\'') {
+				quote := doc[j]
+				attr.ValueStart = j + 1
+				j = attr.ValueStart
+				for j < len(doc) && doc[j] != quote {
+					j++
+				}
+				attr.ValueEnd = j
+				if j < len(doc) {
+					j++
+				}
+			} else {
+				attr.ValueStart = j
+				for j < len(doc) && !isSpace(doc[j]) && doc[j] != '>' {
+					j++
+				}
+				attr.ValueEnd = j
+			}
+
+			attr.Value = string(doc[attr.ValueStart:attr.ValueEnd])
+			i = j
+		} else {
+			attr.ValueStart, attr.ValueEnd = i, i
+		}
+
+		attr.End = i
+		tag.Attrs = append(tag.Attrs, attr)
+	}
+
+	tag.End = len(doc)
+	return tag
+}
+
+// attr returns the named attribute of the tag, if present.
+func (t htmlTag) attr(name string) (htmlAttr, bool) {
+	for _, a := range t.Attrs {
+		if a.Name == name {
+			return a, true
+		}
+	}
+
+	return htmlAttr{}, false
+}
+
+// subresource returns the URL referenced by a script or stylesheet tag, if the tag is one.
+func (t htmlTag) subresource() (string, bool) {
+	switch t.Name {
+	case "script":
+		if src, ok := t.attr("src"); ok && src.Value != "" {
+			return src.Value, true
+		}
+	case "link":
+		rel, _ := t.attr("rel")
+		href, ok := t.attr("href")
+		if !ok || href.Value == "" {
+			return "", false
+		}
+
+		for _, r := range strings.Fields(strings.ToLower(rel.Value)) {
+			if r == "stylesheet" {
+				return href.Value, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// resolveRef resolves a src or href found in a page located in dir to something that can be hashed. Root-relative
+// references are resolved against root. The returned target is either an absolute URL (remote is true) or a local
+// file path. References that can't be hashed, such as data: URIs, are not ok.
+func resolveRef(ref, dir, root string) (target string, remote bool, ok bool) {
+	ref = strings.TrimSpace(ref)
+	if strings.HasPrefix(ref, "//") {
+		ref = "https:" + ref
+	}
+
+	u, err := url.Parse(ref)
+	if err != nil {
+		return "", false, false
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		return u.String(), true, true
+	case "":
+	default:
+		return "", false, false
+	}
+
+	p, err := url.PathUnescape(u.Path)
+	if err != nil || p == "" {
+		return "", false, false
+	}
+
+	if strings.HasPrefix(p, "/") {
+		return filepath.Join(root, filepath.FromSlash(p)), false, true
+	}
+
+	return filepath.Join(dir, filepath.FromSlash(p)), false, true
+}
+
+func skipPast(doc []byte, from int, end string) int {
+	idx := bytes.Index(doc[from:], []byte(end))
+	if idx < 0 {
+		return len(doc)
+	}
+
+	return from + idx + len(end) - 1
+}
+
+func skipRawText(doc []byte, from int, name string) int {
+	closing := []byte("</" + name)
+	for i := from; i+len(closing) <= len(doc); i++ {
+		if doc[i] == '<' && bytes.EqualFold(doc[i:i+len(closing)], closing) {
+			return i
+		}
+	}
+
+	return len(doc)
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == '\f'
+}
+
+func isASCIILetter(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
@@ -0,0 +1,324 @@
+package sri
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	CrossOriginAnonymous      = "anonymous"
+	CrossOriginUseCredentials = "use-credentials"
+)
+
+// ContentTypeAuto expects downloads to be JavaScript or CSS according to the extension of their URL.
+const ContentTypeAuto = "auto"
+
+// javascriptTypes are the JavaScript MIME type essences of the MIME Sniffing standard; the types browsers will
+// execute as scripts.
+var javascriptTypes = map[string]bool{
+	"application/ecmascript":   true,
+	"application/javascript":   true,
+	"application/x-ecmascript": true,
+	"application/x-javascript": true,
+	"text/ecmascript":          true,
+	"text/javascript":          true,
+	"text/javascript1.0":       true,
+	"text/javascript1.1":       true,
+	"text/javascript1.2":       true,
+	"text/javascript1.3":       true,
+	"text/javascript1.4":       true,
+	"text/javascript1.5":       true,
+	"text/jscript":             true,
+	"text/livescript":          true,
+	"text/x-ecmascript":        true,
+	"text/x-javascript":        true,
+}
+
+// Response describes the HTTP response a remote Integrity was produced from.
+type Response struct {
+	URL           string `json:"url"`
+	Status        int    `json:"status"`
+	ContentType   string `json:"content_type,omitempty"`
+	ContentLength int64  `json:"content_length"`
+	ETag          string `json:"etag,omitempty"`
+	LastModified  string `json:"last_modified,omitempty"`
+
+	// AllowOrigin and AllowCredentials are the CORS headers of the response. CORSError describes why the resource
+	// can't be loaded cross-origin with integrity, if it can't.
+	AllowOrigin      string `json:"allow_origin,omitempty"`
+	AllowCredentials bool   `json:"allow_credentials,omitempty"`
+	CORSError        string `json:"cors_error,omitempty"`
+}
+
+func newResponse(resp *http.Response, n int64) *Response {
+	return &Response{
+		URL:           resp.Request.URL.String(),
+		Status:        resp.StatusCode,
+		ContentType:   resp.Header.Get("Content-Type"),
+		ContentLength: n,
+		ETag:          resp.Header.Get("ETag"),
+		LastModified:  resp.Header.Get("Last-Modified"),
+
+		AllowOrigin:      resp.Header.Get("Access-Control-Allow-Origin"),
+		AllowCredentials: resp.Header.Get("Access-Control-Allow-Credentials") == "true",
+	}
+}
+
+// NewClient returns an HTTP client for downloading remote targets which gives up on requests taking longer than
+// timeout, and on requests redirected more than maxRedirects times. The timeout includes reading the response body,
+// which happens after the Generator's retries, so a body read timing out fails the target rather than being retried.
+func NewClient(timeout time.Duration, maxRedirects int) *http.Client {
+	return &http.Client{
+		Timeout: timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > maxRedirects {
+				return fmt.Errorf("Stopped after %d redirects", maxRedirects)
+			}
+
+			return nil
+		},
+	}
+}
+
+// get requests the target URL, retrying timeouts and 5xx responses. Responses which are not 2xx are errors.
+func (g *Generator) get(ctx context.Context, target string) (*http.Response, error) {
+	backoff := g.RetryBackoff
+
+	for attempt := 0; ; attempt++ {
+		resp, err := g.getOnce(ctx, target)
+		if err == nil && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return resp, nil
+		}
+
+		retryable := false
+		if err != nil {
+			ne, ok := err.(net.Error)
+			retryable = ok && ne.Timeout()
+		} else {
+			resp.Body.Close()
+			retryable = resp.StatusCode >= 500
+			err = &StatusError{URL: target, StatusCode: resp.StatusCode, Status: resp.Status}
+		}
+
+		if !retryable || attempt >= g.Retries {
+			return nil, err
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+// StatusError is the error of a download whose response wasn't 2xx.
+type StatusError struct {
+	URL        string
+	StatusCode int
+	Status     string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("Failure downloading script from %s. Received status '%s'", e.URL, e.Status)
+}
+
+func (g *Generator) getOnce(ctx context.Context, target string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, values := range g.Headers {
+		if http.CanonicalHeaderKey(name) == "Authorization" && !g.authHost(req.URL) {
+			continue
+		}
+
+		for _, v := range values {
+			req.Header.Add(name, v)
+		}
+	}
+
+	if g.Origin != "" {
+		req.Header.Set("Origin", g.origin())
+	}
+
+	return g.client().Do(req.WithContext(ctx))
+}
+
+func (g *Generator) client() *http.Client {
+	c := DefaultClient
+	if g.Client != nil {
+		c = g.Client
+	}
+
+	if g.Headers.Get("Authorization") == "" {
+		return c
+	}
+
+	// Credentials are dropped from redirects to hosts they weren't meant for
+	scoped := *c
+	scoped.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if !g.authHost(req.URL) {
+			req.Header.Del("Authorization")
+		}
+
+		if c.CheckRedirect != nil {
+			return c.CheckRedirect(req, via)
+		}
+
+		if len(via) >= 10 {
+			return fmt.Errorf("Stopped after 10 redirects")
+		}
+
+		return nil
+	}
+
+	return &scoped
+}
+
+// authHost reports whether u is on one of the AuthHosts, matched by host name or by host and port.
+func (g *Generator) authHost(u *url.URL) bool {
+	for _, h := range g.AuthHosts {
+		if strings.EqualFold(h, u.Host) || strings.EqualFold(h, u.Hostname()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkContentType returns an error if the Content-Type of resp isn't the type expected by the Generator.
+func (g *Generator) checkContentType(target string, resp *http.Response) error {
+	expected := g.ExpectContentType
+	if expected == ContentTypeAuto {
+		expected = ""
+		if u, err := url.Parse(target); err == nil {
+			switch strings.ToLower(path.Ext(u.Path)) {
+			case ".js", ".mjs":
+				expected = "js"
+			case ".css":
+				expected = "css"
+			}
+		}
+	}
+
+	if expected == "" {
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch {
+	case expected == "js" && javascriptTypes[mediaType]:
+	case expected == "css" && mediaType == "text/css":
+	case expected == mediaType:
+	default:
+		return fmt.Errorf("Received Content-Type '%s', expected %s", resp.Header.Get("Content-Type"), expected)
+	}
+
+	return nil
+}
+
+// checkCORS returns an error if a browser on the Generator's Origin would refuse resp when loading it with the
+// Generator's CrossOrigin mode, following the CORS check of the Fetch standard. Cross-origin resources loaded with
+// CrossOriginNone always fail, as the browser fetches them in no-cors mode and can't check their integrity. No check
+// is made without an Origin, or if the target is on the same origin.
+func (g *Generator) checkCORS(target string, resp *http.Response) error {
+	if g.Origin == "" {
+		return nil
+	}
+
+	if sameOrigin(target, g.Origin) {
+		return nil
+	}
+
+	if g.CrossOrigin == CrossOriginNone {
+		return fmt.Errorf("Cross-origin resources loaded without a crossorigin attribute always fail the integrity check")
+	}
+
+	allowOrigins := resp.Header["Access-Control-Allow-Origin"]
+	switch {
+	case len(allowOrigins) == 0:
+		return fmt.Errorf("No Access-Control-Allow-Origin header, the browser will block the resource")
+	case len(allowOrigins) > 1:
+		return fmt.Errorf("Multiple Access-Control-Allow-Origin headers, the browser will block the resource")
+	}
+
+	allowOrigin, origin := allowOrigins[0], g.origin()
+	if g.CrossOrigin == CrossOriginUseCredentials {
+		if allowOrigin != origin {
+			return fmt.Errorf("Access-Control-Allow-Origin '%s' must be exactly '%s' for crossorigin='%s'", allowOrigin, origin, CrossOriginUseCredentials)
+		}
+
+		if resp.Header.Get("Access-Control-Allow-Credentials") != "true" {
+			return fmt.Errorf("Access-Control-Allow-Credentials must be 'true' for crossorigin='%s'", CrossOriginUseCredentials)
+		}
+
+		return nil
+	}
+
+	if allowOrigin != "*" && allowOrigin != origin {
+		return fmt.Errorf("Access-Control-Allow-Origin '%s' does not allow origin '%s'", allowOrigin, origin)
+	}
+
+	return nil
+}
+
+// origin returns the Origin of the Generator as browsers send it, without a trailing slash.
+func (g *Generator) origin() string {
+	return strings.TrimSuffix(g.Origin, "/")
+}
+
+// sameOrigin reports whether target is on origin; sharing its scheme, host and port, where a missing port is the
+// default port of the scheme.
+func sameOrigin(target, origin string) bool {
+	t, err := url.Parse(target)
+	if err != nil {
+		return false
+	}
+
+	o, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+
+	return strings.EqualFold(t.Scheme, o.Scheme) && strings.EqualFold(t.Hostname(), o.Hostname()) &&
+		effectivePort(t) == effectivePort(o)
+}
+
+// effectivePort returns the port of u, or the default port of its scheme if it has none.
+func effectivePort(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return port
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "http":
+		return "80"
+	case "https":
+		return "443"
+	}
+
+	return ""
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
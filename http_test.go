@@ -0,0 +1,293 @@
+package sri
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDownloadRetries(t *testing.T) {
+	requests := 0
+	serve := func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		w.Write([]byte("console.log('hello world!');"))
+	}
+	mockServer := httptest.NewServer(http.HandlerFunc(serve))
+	defer mockServer.Close()
+
+	g := &Generator{Hash: SHA256, Client: mockServer.Client(), Retries: 1, RetryBackoff: time.Millisecond}
+	if _, err := g.Download(mockServer.URL); err == nil || !strings.Contains(err.Error(), "502 Bad Gateway") {
+		t.Fatalf("Expected a 502 error after exhausting retries. Got %q", err)
+	}
+
+	requests = 0
+	g.Retries = 2
+	fis, err := g.Download(mockServer.URL)
+	if err != nil {
+		t.Fatalf("Unexpected error from Download call. %q", err)
+	}
+
+	if exp := "sha256-lClGOfcWqtQdAvO3zCRzZEg/4RmOMbr9/V54QO76j/A="; fis[0].Digest != exp {
+		t.Fatalf("Expected digest %s. Got %s", exp, fis[0].Digest)
+	}
+}
+
+func TestDownloadRejectsErrorResponses(t *testing.T) {
+	requests := 0
+	serve := func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.NotFound(w, r)
+	}
+	mockServer := httptest.NewServer(http.HandlerFunc(serve))
+	defer mockServer.Close()
+
+	g := &Generator{Hash: SHA256, Client: mockServer.Client(), Retries: 3}
+	if _, err := g.Download(mockServer.URL); err == nil || !strings.Contains(err.Error(), "404 Not Found") {
+		t.Fatalf("Expected a 404 error. Got %q", err)
+	}
+
+	if requests != 1 {
+		t.Fatalf("Expected a 404 not to be retried. Got %d requests", requests)
+	}
+}
+
+func TestDownloadHeaders(t *testing.T) {
+	serve := func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" || r.Header.Get("X-Custom") != "custom" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.Write([]byte("console.log('hello world!');"))
+	}
+	mockServer := httptest.NewServer(http.HandlerFunc(serve))
+	defer mockServer.Close()
+
+	g := &Generator{Hash: SHA256, Client: mockServer.Client()}
+	if _, err := g.Download(mockServer.URL); err == nil {
+		t.Fatalf("Expected an error downloading without headers")
+	}
+
+	g.Headers = http.Header{"Authorization": {"Bearer token"}, "X-Custom": {"custom"}}
+	g.AuthHosts = []string{strings.TrimPrefix(mockServer.URL, "http://")}
+	if _, err := g.Download(mockServer.URL); err != nil {
+		t.Fatalf("Unexpected error from Download call with headers. %q", err)
+	}
+}
+
+func TestDownloadAuthHosts(t *testing.T) {
+	thirdParty := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			t.Errorf("Expected no Authorization header sent to %s. Got '%s'", r.URL, auth)
+		}
+
+		w.Write([]byte("console.log('hello world!');"))
+	}))
+	defer thirdParty.Close()
+
+	private := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, thirdParty.URL+"/app.js", http.StatusFound)
+			return
+		}
+
+		w.Write([]byte("console.log('hello world!');"))
+	}))
+	defer private.Close()
+
+	g := &Generator{
+		Hash:      SHA256,
+		Client:    NewClient(time.Second, 10),
+		Headers:   http.Header{"Authorization": {"Bearer token"}},
+		AuthHosts: []string{strings.TrimPrefix(private.URL, "http://")},
+	}
+
+	for _, target := range []string{private.URL + "/app.js", thirdParty.URL + "/app.js", private.URL + "/redirect"} {
+		if _, err := g.Download(target); err != nil {
+			t.Fatalf("Unexpected error downloading %s. %q", target, err)
+		}
+	}
+}
+
+func TestNewClientMaxRedirects(t *testing.T) {
+	serve := func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/app.js" {
+			w.Write([]byte("console.log('hello world!');"))
+			return
+		}
+
+		http.Redirect(w, r, "/app.js", http.StatusFound)
+	}
+	mockServer := httptest.NewServer(http.HandlerFunc(serve))
+	defer mockServer.Close()
+
+	g := &Generator{Hash: SHA256, Client: NewClient(time.Second, 0)}
+	if _, err := g.Download(mockServer.URL + "/redirect"); err == nil {
+		t.Fatalf("Expected an error following a redirect with max redirects of 0")
+	}
+
+	g.Client = NewClient(time.Second, 1)
+	if _, err := g.Download(mockServer.URL + "/redirect"); err != nil {
+		t.Fatalf("Unexpected error from Download call. %q", err)
+	}
+}
+
+func TestDownloadValidation(t *testing.T) {
+	serve := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/app.js":
+			w.Header().Set("Content-Type", "application/javascript; charset=utf-8")
+			w.Header().Set("ETag", `"abc"`)
+		case "/app.css":
+			w.Header().Set("Content-Type", "text/html")
+		}
+
+		w.Write([]byte("console.log('hello world!');"))
+	}
+	mockServer := httptest.NewServer(http.HandlerFunc(serve))
+	defer mockServer.Close()
+
+	type testCase struct {
+		path       string
+		expectType string
+		minSize    int64
+		errMsg     string
+	}
+
+	testCases := []testCase{
+		{path: "/app.js", expectType: ContentTypeAuto},
+		{path: "/app.js", expectType: "js", minSize: 28},
+		{path: "/app.js", expectType: "application/javascript"},
+		{path: "/app.js", expectType: "css", errMsg: "Received Content-Type 'application/javascript; charset=utf-8', expected css"},
+		{path: "/app.js", minSize: 29, errMsg: "Received 28 bytes, expected at least 29"},
+		{path: "/app.css", expectType: ContentTypeAuto, errMsg: "Received Content-Type 'text/html', expected css"},
+		{path: "/other", expectType: ContentTypeAuto},
+	}
+
+	for _, tc := range testCases {
+		g := &Generator{Hash: SHA256, Client: mockServer.Client(), ExpectContentType: tc.expectType, MinSize: tc.minSize}
+		fis, err := g.Download(mockServer.URL + tc.path)
+
+		if tc.errMsg != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.errMsg) {
+				t.Fatalf("Expected error containing '%s'. Got %q", tc.errMsg, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("Unexpected error from Download call (%s). %q", tc.path, err)
+		}
+
+		if r := fis[0].Response; r == nil || r.Status != 200 || r.ContentLength != 28 || r.URL != mockServer.URL+tc.path {
+			t.Fatalf("Unexpected response metadata %+v", r)
+		}
+	}
+}
+
+func TestSameOrigin(t *testing.T) {
+	type testCase struct {
+		target string
+		origin string
+		exp    bool
+	}
+
+	testCases := []testCase{
+		{"https://example.com/app.js", "https://example.com", true},
+		{"https://example.com/app.js", "https://example.com/", true},
+		{"https://example.com:443/app.js", "https://EXAMPLE.com", true},
+		{"http://example.com/app.js", "http://example.com:80", true},
+		{"http://example.com/app.js", "https://example.com", false},
+		{"https://example.com:8443/app.js", "https://example.com", false},
+		{"https://cdn.example.com/app.js", "https://example.com", false},
+	}
+
+	for _, tc := range testCases {
+		if same := sameOrigin(tc.target, tc.origin); same != tc.exp {
+			t.Fatalf("Expected same origin of %s and %s to be %t. Got %t", tc.target, tc.origin, tc.exp, same)
+		}
+	}
+}
+
+func TestCheckCORS(t *testing.T) {
+	origin := "https://example.com"
+
+	type testCase struct {
+		crossOrigin      string
+		allowOrigin      []string
+		allowCredentials string
+		ok               bool
+	}
+
+	testCases := []testCase{
+		{CrossOriginAnonymous, nil, "", false},
+		{CrossOriginAnonymous, []string{"*"}, "", true},
+		{CrossOriginAnonymous, []string{origin}, "", true},
+		{CrossOriginAnonymous, []string{"https://other.com"}, "", false},
+		{CrossOriginAnonymous, []string{"*", origin}, "", false},
+		{CrossOriginUseCredentials, []string{"*"}, "true", false},
+		{CrossOriginUseCredentials, []string{origin}, "", false},
+		{CrossOriginUseCredentials, []string{origin}, "true", true},
+		{CrossOriginNone, []string{"*"}, "", false},
+		{CrossOriginNone, []string{origin}, "true", false},
+	}
+
+	for _, tc := range testCases {
+		serve := func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Origin") != origin {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			for _, o := range tc.allowOrigin {
+				w.Header().Add("Access-Control-Allow-Origin", o)
+			}
+			if tc.allowCredentials != "" {
+				w.Header().Set("Access-Control-Allow-Credentials", tc.allowCredentials)
+			}
+
+			w.Write([]byte("console.log('hello world!');"))
+		}
+		mockServer := httptest.NewServer(http.HandlerFunc(serve))
+
+		g := &Generator{Hash: SHA256, Client: mockServer.Client(), Origin: origin, CrossOrigin: tc.crossOrigin}
+		fis, err := g.Download(mockServer.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error from Download call. %q", err)
+		}
+
+		if ok := fis[0].Response.CORSError == ""; ok != tc.ok {
+			t.Fatalf("Expected CORS check of %+v to be %t. Got error '%s'", tc, tc.ok, fis[0].Response.CORSError)
+		}
+
+		g.StrictCORS = true
+		if _, err := g.Download(mockServer.URL); (err == nil) != tc.ok {
+			t.Fatalf("Expected strict CORS check of %+v to be %t. Got %q", tc, tc.ok, err)
+		}
+
+		mockServer.Close()
+	}
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", r.Header.Get("Origin"))
+		w.Write([]byte("console.log('hello world!');"))
+	}))
+	defer mockServer.Close()
+
+	g := &Generator{Hash: SHA256, Client: mockServer.Client(), Origin: origin + "/", StrictCORS: true}
+	if _, err := g.Download(mockServer.URL); err != nil {
+		t.Fatalf("Expected an origin with a trailing slash to be sent and allowed without it. Got %q", err)
+	}
+}
@@ -0,0 +1,104 @@
+package sri
+
+import (
+	"context"
+	"path"
+	"sort"
+	"strings"
+)
+
+// ImportMap is an import map, as found in a <script type="importmap">, including its integrity section mapping
+// module URLs to their integrity metadata.
+type ImportMap struct {
+	Imports   map[string]string            `json:"imports,omitempty"`
+	Scopes    map[string]map[string]string `json:"scopes,omitempty"`
+	Integrity map[string]string            `json:"integrity,omitempty"`
+}
+
+// moduleExts are the extensions of files treated as modules when building an import map from a directory.
+var moduleExts = map[string]bool{".js": true, ".mjs": true}
+
+// ImportMapFromDir builds an import map whose integrity section covers every module in dir. Each module is keyed
+// by its path relative to dir, appended to prefix, e.g '/modules/' + 'app.js'. A '/' is added to a prefix without
+// one, so '/modules' keys the same modules.
+func (g *Generator) ImportMapFromDir(dir, prefix string) (*ImportMap, error) {
+	files, err := g.walk(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	jobs := []job{}
+	for _, wf := range files {
+		if len(g.Include) > 0 || moduleExts[strings.ToLower(path.Ext(wf.Rel))] {
+			jobs = append(jobs, job{target: wf.Path, name: prefix + wf.Rel, kind: SourceFile})
+		}
+	}
+
+	m := &ImportMap{}
+	return m, g.fillIntegrity(m, jobs)
+}
+
+// UpdateImportMap sets the integrity of every module address in the imports and scopes of m. Remote addresses are
+// downloaded, while relative addresses are resolved as local files as they are by Inject; against dir, or root if
+// they begin with '/'. Addresses ending in '/' map whole prefixes, so have no single integrity and are skipped.
+func (g *Generator) UpdateImportMap(m *ImportMap, dir, root string) error {
+	addresses := make(map[string]bool)
+	for _, address := range m.Imports {
+		addresses[address] = true
+	}
+	for _, scope := range m.Scopes {
+		for _, address := range scope {
+			addresses[address] = true
+		}
+	}
+
+	jobs := []job{}
+	for address := range addresses {
+		if strings.HasSuffix(address, "/") {
+			continue
+		}
+
+		target, remote, ok := resolveRef(address, dir, root)
+		if !ok {
+			continue
+		}
+
+		kind := SourceFile
+		if remote {
+			kind = SourceURL
+		}
+
+		jobs = append(jobs, job{target: target, name: address, kind: kind})
+	}
+
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].name < jobs[j].name })
+
+	return g.fillIntegrity(m, jobs)
+}
+
+// fillIntegrity hashes the jobs, setting the integrity of each in m by its name.
+func (g *Generator) fillIntegrity(m *ImportMap, jobs []job) error {
+	fis, errs := g.run(context.Background(), jobs)
+	if len(errs) > 0 {
+		return errs
+	}
+
+	if m.Integrity == nil {
+		m.Integrity = make(map[string]string)
+	}
+
+	files, err := g.TemplateFiles(fis)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		m.Integrity[f.Path] = f.Integrity
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package sri
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestImportMapFromDir(t *testing.T) {
+	g := &Generator{Hash: SHA256, Exclude: []string{"compare-*"}}
+
+	exp := map[string]string{
+		"/modules/test.js":     "sha256-jEUM4jWrIiMerWo9zYrx6XwQ5eI77uzuETBptBvPlRQ=",
+		"/modules/test.min.js": "sha256-ODBnPrz8p2bs/l/ffyD4jUqpRkTvzlmFu8WDCuYNYms=",
+	}
+
+	for _, prefix := range []string{"/modules/", "/modules"} {
+		m, err := g.ImportMapFromDir("./test", prefix)
+		if err != nil {
+			t.Fatalf("Unexpected error from ImportMapFromDir call. %q", err)
+		}
+
+		if !reflect.DeepEqual(m.Integrity, exp) {
+			t.Fatalf("Expected integrity %q with prefix %s. Got %q", exp, prefix, m.Integrity)
+		}
+	}
+}
+
+func TestUpdateImportMap(t *testing.T) {
+	m := &ImportMap{
+		Imports: map[string]string{
+			"app":   "./test.js",
+			"libs/": "./libs/",
+		},
+		Scopes: map[string]map[string]string{
+			"/legacy/": {"app": "/test/test.min.js"},
+		},
+		Integrity: map[string]string{"/existing.js": "sha256-existing"},
+	}
+
+	g := &Generator{Hash: "sha256,sha384"}
+	if err := g.UpdateImportMap(m, "test", "."); err != nil {
+		t.Fatalf("Unexpected error from UpdateImportMap call. %q", err)
+	}
+
+	exp := map[string]string{
+		"/existing.js": "sha256-existing",
+		"./test.js": "sha256-jEUM4jWrIiMerWo9zYrx6XwQ5eI77uzuETBptBvPlRQ= " +
+			"sha384-zBTHeP/UZLYRhjvTi7r3Dx7MTCNf/ddGENI26AacmrgqzH8YOkA+EJ14MXpwD4wL",
+		"/test/test.min.js": "sha256-ODBnPrz8p2bs/l/ffyD4jUqpRkTvzlmFu8WDCuYNYms= " +
+			"sha384-GFSKzS/+oGDIT70dABnjqACvEFXH8kCG4tW9e3athjSbADyCkj3Mlfk1a2mmtAWa",
+	}
+
+	if !reflect.DeepEqual(m.Integrity, exp) {
+		t.Fatalf("Expected integrity %q. Got %q", exp, m.Integrity)
+	}
+
+	m.Imports["missing"] = "./not-real.js"
+	if err := g.UpdateImportMap(m, "test", "."); err == nil {
+		t.Fatalf("Expected an error updating an import map with a missing module")
+	}
+}
@@ -0,0 +1,111 @@
+package sri
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Injection is a change made by Inject to a single script or stylesheet tag.
+type Injection struct {
+	Line int    `json:"line"`
+	Ref  string `json:"ref"`
+	Old  string `json:"old"`
+	New  string `json:"new"`
+}
+
+// Inject adds or updates the integrity attribute of every script and stylesheet tag in an HTML document, adding a
+// crossorigin attribute of the Generator's CrossOrigin mode (anonymous by default, none for CrossOriginNone) to those
+// referencing remote URLs if they don't already have one. References are resolved relative to dir, or to root if
+// they begin with '/'. The rest of the document is left untouched.
+func (g *Generator) Inject(doc []byte, dir, root string) ([]byte, []Injection, error) {
+	injections := []Injection{}
+	var out bytes.Buffer
+
+	last := 0
+	for _, tag := range scanTags(doc) {
+		ref, ok := tag.subresource()
+		if !ok {
+			continue
+		}
+
+		target, remote, ok := resolveRef(ref, dir, root)
+		if !ok {
+			continue
+		}
+
+		integrity, err := g.integrityAttr(target, remote)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Unable to generate integrity for %s (line %d). %s", ref, tag.Line, err)
+		}
+
+		old := string(doc[tag.Start:tag.End])
+		updated := setAttr(doc, tag, "integrity", integrity)
+		if _, ok := tag.attr("crossorigin"); remote && !ok {
+			if crossOrigin := g.crossOrigin(target); crossOrigin != "" {
+				updated = insertAttr(updated, "crossorigin", crossOrigin)
+			}
+		}
+
+		if updated == old {
+			continue
+		}
+
+		out.Write(doc[last:tag.Start])
+		out.WriteString(updated)
+		last = tag.End
+
+		injections = append(injections, Injection{Line: tag.Line, Ref: ref, Old: old, New: updated})
+	}
+	out.Write(doc[last:])
+
+	return out.Bytes(), injections, nil
+}
+
+// integrityAttr hashes target with every algorithm of the Generator, returning the digests as a single integrity
+// attribute value.
+func (g *Generator) integrityAttr(target string, remote bool) (string, error) {
+	fis, err := g.resolved(target, remote)
+	if err != nil {
+		return "", err
+	}
+
+	return joinDigests(fis), nil
+}
+
+// setAttr returns the text of tag with the named attribute set to value, replacing the existing value if present.
+func setAttr(doc []byte, tag htmlTag, name, value string) string {
+	a, ok := tag.attr(name)
+	if !ok {
+		return insertAttr(string(doc[tag.Start:tag.End]), name, value)
+	}
+
+	if a.ValueStart == a.End {
+		// Bare attribute, e.g. <script src="app.js" integrity>
+		return string(doc[tag.Start:a.End]) + fmt.Sprintf(`="%s"`, value) + string(doc[a.End:tag.End])
+	}
+
+	return string(doc[tag.Start:a.ValueStart]) + value + string(doc[a.ValueEnd:tag.End])
+}
+
+// insertAttr adds name="value" to the end of the given start tag, before any self-closing slash.
+func insertAttr(tag, name, value string) string {
+	end := strings.TrimSuffix(tag, ">")
+	closing := ">"
+	if strings.HasSuffix(end, "/") {
+		end = strings.TrimSuffix(end, "/")
+		closing = "/>"
+	}
+
+	trimmed := strings.TrimRight(end, " \t\r\n\f")
+	return trimmed + fmt.Sprintf(` %s="%s"`, name, value) + end[len(trimmed):] + closing
+}
+
+// resolved produces the integrities of a target returned by resolveRef.
+func (g *Generator) resolved(target string, remote bool) ([]Integrity, error) {
+	if remote {
+		return g.Download(target)
+	}
+
+	return g.File(target)
+}
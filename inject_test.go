@@ -0,0 +1,88 @@
+package sri
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestInject(t *testing.T) {
+	doc := `<!DOCTYPE html>
+<html>
+<head>
+	<!-- <script src="commented-out.js"></script> -->
+	<link rel="stylesheet" href="test/test.css" />
+	<link rel="icon" href="favicon.ico">
+	<script src='/test/test.js' integrity="sha256-stale" async></script>
+	<script>var s = '<script src="inline.js"></script>';</script>
+</head>
+<body><script src="data:text/javascript,1"></script></body>
+</html>
+`
+
+	g := &Generator{Hash: SHA256}
+	out, injections, err := g.Inject([]byte(doc), ".", ".")
+	if err != nil {
+		t.Fatalf("Unexpected error from Inject call. %q", err)
+	}
+
+	if len(injections) != 2 {
+		t.Fatalf("Expected 2 injections. Got %d (%+v)", len(injections), injections)
+	}
+
+	exp := strings.Replace(doc,
+		`<link rel="stylesheet" href="test/test.css" />`,
+		`<link rel="stylesheet" href="test/test.css" integrity="sha256-ckxnbs3D4win9ik/Eh1/55cPi1yJ4xBVTU5npga+uw8=" />`, 1)
+	exp = strings.Replace(exp,
+		`<script src='/test/test.js' integrity="sha256-stale" async>`,
+		`<script src='/test/test.js' integrity="sha256-jEUM4jWrIiMerWo9zYrx6XwQ5eI77uzuETBptBvPlRQ=" async>`, 1)
+
+	if string(out) != exp {
+		t.Fatalf("Unexpected output from Inject call. Got:\n%s", out)
+	}
+
+	if injections[0].Line != 5 || injections[1].Line != 7 {
+		t.Fatalf("Expected injections on lines 5 and 7. Got %d and %d", injections[0].Line, injections[1].Line)
+	}
+
+	if _, again, _ := g.Inject(out, ".", "."); len(again) != 0 {
+		t.Fatalf("Expected injecting an up to date document to make no changes. Got %+v", again)
+	}
+}
+
+func TestInjectRemote(t *testing.T) {
+	serve := func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("console.log('hello world!');")) }
+	mockServer := httptest.NewServer(http.HandlerFunc(serve))
+	defer mockServer.Close()
+
+	g := &Generator{Hash: SHA256, Client: mockServer.Client()}
+	doc := fmt.Sprintf(`<script src="%s/app.js"></script>`, mockServer.URL)
+
+	out, _, err := g.Inject([]byte(doc), ".", ".")
+	if err != nil {
+		t.Fatalf("Unexpected error from Inject call. %q", err)
+	}
+
+	exp := fmt.Sprintf(`<script src="%s/app.js" integrity="sha256-lClGOfcWqtQdAvO3zCRzZEg/4RmOMbr9/V54QO76j/A=" crossorigin="anonymous"></script>`, mockServer.URL)
+	if string(out) != exp {
+		t.Fatalf("Expected %s. Got %s", exp, out)
+	}
+
+	for crossOrigin, attr := range map[string]string{
+		CrossOriginUseCredentials: ` crossorigin="use-credentials"`,
+		CrossOriginNone:           "",
+	} {
+		g.CrossOrigin = crossOrigin
+		out, _, err := g.Inject([]byte(doc), ".", ".")
+		if err != nil {
+			t.Fatalf("Unexpected error from Inject call. %q", err)
+		}
+
+		exp := fmt.Sprintf(`<script src="%s/app.js" integrity="sha256-lClGOfcWqtQdAvO3zCRzZEg/4RmOMbr9/V54QO76j/A="%s></script>`, mockServer.URL, attr)
+		if string(out) != exp {
+			t.Fatalf("Expected %s with crossorigin %s. Got %s", exp, crossOrigin, out)
+		}
+	}
+}
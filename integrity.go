@@ -1,8 +1,7 @@
-package main
+// Package sri generates and compares Sub-Resource Integrity digests for URLs, files and directories.
+package sri
 
 import (
-	"crypto/sha256"
-	"crypto/sha512"
 	"encoding/base64"
 	"fmt"
 	"hash"
@@ -10,70 +9,169 @@ import (
 	"net/url"
 	"path"
 	"sort"
+	"strings"
 )
 
-type fileIntegrity struct {
+// Integrity is the digest of a single file for a single hashing algorithm, along with a tag referencing it. When
+// generating with Combine, there is a single Integrity per file whose Digest carries the digests of every algorithm.
+//
+// FileName identifies the file; the full URL of remote files, the path of files relative to the directory target
+// they were found in, or the base name of files that were targeted directly. Source is the URL or path the file was
+// read from, or the public URL it is served from when generating with URLs; in which case Path is the local path it
+// was read from.
+type Integrity struct {
 	Digest   string `json:"digest"`
 	FileName string `json:"file"`
 	Tag      string `json:"tag"`
 	Source   string `json:"source,omitempty"`
+	Path     string `json:"path,omitempty"`
+
+	// Integrity is the combined integrity attribute value carrying the digests of every selected algorithm, set
+	// (along with Digest) when generating with Combine.
+	Integrity string `json:"integrity,omitempty"`
+
+	// Preload and Link are a preload tag and Link header value for the file, set when generating with Preload.
+	Preload string `json:"preload,omitempty"`
+	Link    string `json:"link,omitempty"`
+
+	// Size is the number of bytes hashed.
+	Size int64 `json:"size"`
+
+	// Environments are the public URL and tags of the file in each of the Environments of the Generator.
+	Environments map[string]Environment `json:"environments,omitempty"`
+
+	// Response describes the HTTP response the digest of a remote file was produced from.
+	Response *Response `json:"response,omitempty"`
 }
 
-type integrities []fileIntegrity
+// Integrities is a list of Integrity, sortable by file name.
+type Integrities []Integrity
 
-var _ sort.Interface = (integrities)(nil)
+var _ sort.Interface = (Integrities)(nil)
 
-func generateFileIntegrities(source, hashAlgo string, r io.Reader) ([]fileIntegrity, error) {
-	var hs []io.Writer
-	if hashAlgo == allHashes {
-		hs = []io.Writer{
-			sha256.New(),
-			sha512.New384(),
-			sha512.New(),
-		}
-	} else {
-		hs = []io.Writer{hashes[hashAlgo]()}
+// Integrities hashes the contents of r, producing an Integrity per hashing algorithm of the Generator, or a single
+// Integrity carrying every digest with Combine.
+func (g *Generator) Integrities(source string, r io.Reader) ([]Integrity, error) {
+	// The content is also hashed with the algorithm a browser would check Expect with, if it isn't already selected
+	var extra []string
+	if algo := strongestAlgorithm(g.Expect); algo != "" && !containsString(hashNames(g.Hash), algo) {
+		extra = append(extra, algo)
 	}
 
-	multiHasher := io.MultiWriter(hs...)
-	if _, err := io.Copy(multiHasher, r); err != nil {
+	digests, n, err := g.digests(r, extra...)
+	if err != nil {
 		return nil, err
 	}
 
-	fiChan := make(chan string, len(hs))
-	for _, h := range hs {
-		go func(h hash.Hash) {
-			fiChan <- fmt.Sprintf("sha%d-%s", h.Size()*8, base64.StdEncoding.EncodeToString(h.Sum(nil)))
-		}(h.(hash.Hash))
+	if !matchDigests(g.Expect, digests) {
+		return nil, fmt.Errorf("%s does not match the expected integrity '%s'. Got '%s'", source, g.Expect,
+			strings.Join(digests, " "))
+	}
+	digests = digests[:len(digests)-len(extra)]
+
+	combined := strings.Join(digests, " ")
+	if g.Combine {
+		digests = []string{combined}
 	}
 
-	fis := []fileIntegrity{}
-	for i := 0; i < len(hs); i++ {
-		digest := <-fiChan
-		fi := fileIntegrity{
+	fis := []Integrity{}
+	for _, digest := range digests {
+		fi := Integrity{
 			Digest:   digest,
 			FileName: path.Base(source),
-			Tag:      generateTag(source, digest),
+			Source:   source,
+			Size:     n,
 		}
 
-		if _, err := url.ParseRequestURI(source); err == nil {
-			fi.Source = source
+		if isRemote(source) {
+			fi.FileName = source
+		}
+
+		if g.Combine {
+			fi.Integrity = combined
 		}
 
 		fis = append(fis, fi)
 	}
 
+	if err := g.renderTags(fis); err != nil {
+		return nil, err
+	}
+
 	return fis, nil
 }
 
-func generateTag(source, digest string) string {
-	if path.Ext(source) == ".css" {
-		return fmt.Sprintf(`<link rel='stylesheet' href='%s' integrity='%s'>`, source, digest)
+// digests hashes the contents of r with each hashing algorithm of the Generator, followed by any extra algorithms,
+// returning the digests along with the number of bytes hashed.
+func (g *Generator) digests(r io.Reader, extra ...string) ([]string, int64, error) {
+	var hs []io.Writer
+	for _, name := range append(hashNames(g.Hash), extra...) {
+		hs = append(hs, hashes[name]())
+	}
+
+	multiHasher := io.MultiWriter(hs...)
+	n, err := io.Copy(multiHasher, r)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	digests := make([]string, len(hs))
+	for i, h := range hs {
+		h := h.(hash.Hash)
+		digests[i] = fmt.Sprintf("sha%d-%s", h.Size()*8, base64.StdEncoding.EncodeToString(h.Sum(nil)))
+	}
+
+	return digests, n, nil
+}
+
+// renderTags sets the Tag of each of the integrities of a single file, using the TagTemplate of the Generator if it
+// has one.
+func (g *Generator) renderTags(fis []Integrity) error {
+	for i, fi := range fis {
+		integrity := fi.Digest
+		if fi.Integrity != "" {
+			integrity = fi.Integrity
+		}
+
+		if g.Preload {
+			fis[i].Preload = g.tags().RenderPreload(fi.Source, integrity)
+			fis[i].Link = g.tags().LinkHeader(fi.Source, integrity)
+		}
+
+		if g.TagTemplate == nil {
+			fis[i].Tag = g.tags().Render(fi.Source, integrity)
+			continue
+		}
+
+		var b strings.Builder
+		if err := g.TagTemplate.Execute(&b, g.newTemplateFile(fis, integrity)); err != nil {
+			return fmt.Errorf("Unable to render tag for %s. %s", fi.FileName, err)
+		}
+		fis[i].Tag = b.String()
+	}
+
+	return nil
+}
+
+// localSource returns the URL or path the file was read from, rather than its public URL.
+func (fi Integrity) localSource() string {
+	if fi.Path != "" {
+		return fi.Path
+	}
+
+	return fi.Source
+}
+
+// isRemote reports whether source is an http(s) URL.
+func isRemote(source string) bool {
+	u, err := url.Parse(source)
+	if err != nil {
+		return false
 	}
 
-	return fmt.Sprintf(`<script src='%s' integrity='%s'></script>`, source, digest)
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
 }
 
-func (is integrities) Len() int           { return len(is) }
-func (is integrities) Swap(i, j int)      { is[i], is[j] = is[j], is[i] }
-func (is integrities) Less(i, j int) bool { return is[i].FileName < is[j].FileName }
+func (is Integrities) Len() int           { return len(is) }
+func (is Integrities) Swap(i, j int)      { is[i], is[j] = is[j], is[i] }
+func (is Integrities) Less(i, j int) bool { return is[i].FileName < is[j].FileName }
@@ -1,4 +1,4 @@
-package main
+package sri
 
 import (
 	"net/http"
@@ -11,7 +11,7 @@ func TestGenerate(t *testing.T) {
 	serve := func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("console.log('hello world!');")) }
 	stubJSFileHandler := http.HandlerFunc(serve)
 	mockServer := httptest.NewServer(stubJSFileHandler)
-	client = mockServer.Client()
+	defer mockServer.Close()
 
 	type testCase struct {
 		targets []string
@@ -98,8 +98,9 @@ func TestGenerate(t *testing.T) {
 
 	for _, tc := range testCases {
 		// Test against each of our hash options
-		for _, h := range []string{sha256Algo, sha384Algo, sha512Algo, allHashes} {
-			fis, err := generate(tc.targets, h)
+		for _, h := range []string{SHA256, SHA384, SHA512, AllHashes} {
+			g := &Generator{Hash: h, Client: mockServer.Client()}
+			fis, err := g.Generate(tc.targets)
 			if err != nil {
 				t.Fatalf("Unexpected error from generate call (targets: %q). %q", tc.targets, err)
 			}
@@ -120,3 +121,36 @@ func TestGenerate(t *testing.T) {
 		}
 	}
 }
+
+func TestGenerateCombined(t *testing.T) {
+	g := &Generator{Hash: "sha384,sha256", Combine: true}
+	fis, err := g.Generate([]string{"test/test.js"})
+	if err != nil {
+		t.Fatalf("Unexpected error from Generate call (target: 'test/test.js'). %q", err)
+	}
+
+	if len(fis) != 1 {
+		t.Fatalf("Expected a single combined integrity. Got %d", len(fis))
+	}
+
+	exp := "sha256-jEUM4jWrIiMerWo9zYrx6XwQ5eI77uzuETBptBvPlRQ= " +
+		"sha384-zBTHeP/UZLYRhjvTi7r3Dx7MTCNf/ddGENI26AacmrgqzH8YOkA+EJ14MXpwD4wL"
+	expTag := "<script src='test/test.js' integrity='" + exp + "'></script>"
+
+	if fi := fis[0]; fi.Digest != exp || fi.Integrity != exp {
+		t.Fatalf("Expected combined digest and integrity '%s'. Got '%s' and '%s'", exp, fi.Digest, fi.Integrity)
+	}
+
+	if fis[0].Tag != expTag {
+		t.Fatalf("Expected combined tag '%s'. Got '%s'", expTag, fis[0].Tag)
+	}
+
+	m := NewManifest(fis)
+	if _, ok := m["test.js"]["sha256,sha384"]; !ok {
+		t.Fatalf("Expected the combined integrity in the manifest as 'sha256,sha384'. Got %+v", m)
+	}
+
+	if result := (&Generator{Hash: SHA384}).VerifySources(m, "."); !result.OK() {
+		t.Fatalf("Expected a manifest of combined integrities to verify. Got %+v", result)
+	}
+}
@@ -0,0 +1,122 @@
+package sri
+
+import "strings"
+
+// algorithmPriority ranks the supported algorithms by strength, as browsers do when choosing which of an integrity
+// attribute's digests to check.
+var algorithmPriority = map[string]int{
+	SHA256: 1,
+	SHA384: 2,
+	SHA512: 3,
+}
+
+// Metadata is a single hash-expression of an integrity attribute, e.g 'sha384-oqVu...?opt'.
+type Metadata struct {
+	Algorithm string `json:"algorithm"`
+	Value     string `json:"value"`
+	Options   string `json:"options,omitempty"`
+}
+
+// Digest returns the metadata in the same form as the Digest of an Integrity, without any options.
+func (m Metadata) Digest() string {
+	return m.Algorithm + "-" + m.Value
+}
+
+// digestAlgorithm returns the algorithm of a digest produced by a Generator, e.g 'sha256'.
+func digestAlgorithm(digest string) string {
+	if ms := ParseMetadata(digest); len(ms) > 0 {
+		return ms[0].Algorithm
+	}
+
+	return ""
+}
+
+// ParseMetadata parses an integrity attribute value following the W3C SRI 'parse metadata' algorithm. The value is
+// split on whitespace into hash-expressions, any '?options' suffix is separated out, and expressions using an
+// unknown algorithm are ignored.
+func ParseMetadata(integrity string) []Metadata {
+	result := []Metadata{}
+
+	for _, token := range strings.Fields(integrity) {
+		expression, options := token, ""
+		if i := strings.Index(token, "?"); i >= 0 {
+			expression, options = token[:i], token[i+1:]
+		}
+
+		i := strings.Index(expression, "-")
+		if i < 0 {
+			continue
+		}
+
+		algo := strings.ToLower(expression[:i])
+		if _, ok := algorithmPriority[algo]; !ok {
+			continue
+		}
+
+		result = append(result, Metadata{Algorithm: algo, Value: expression[i+1:], Options: options})
+	}
+
+	return result
+}
+
+// StrongestMetadata returns the metadata using the strongest algorithm present in the list, which are the only
+// metadata a browser will check.
+func StrongestMetadata(ms []Metadata) []Metadata {
+	strongest := 0
+	for _, m := range ms {
+		if p := algorithmPriority[m.Algorithm]; p > strongest {
+			strongest = p
+		}
+	}
+
+	result := []Metadata{}
+	for _, m := range ms {
+		if algorithmPriority[m.Algorithm] == strongest {
+			result = append(result, m)
+		}
+	}
+
+	return result
+}
+
+// Match reports whether a browser would accept content with the given integrities against an integrity attribute
+// value. As in the browser, an attribute with no valid metadata always matches, otherwise any one of the digests
+// using the strongest algorithm must match. The integrities should include every algorithm, e.g by generating with
+// AllHashes.
+func Match(integrity string, fis []Integrity) bool {
+	digests := []string{}
+	for _, fi := range fis {
+		digests = append(digests, strings.Fields(fi.Digest)...)
+	}
+
+	return matchDigests(integrity, digests)
+}
+
+// matchDigests reports whether a browser would accept content with the given digests, as Match does.
+func matchDigests(integrity string, digests []string) bool {
+	ms := ParseMetadata(integrity)
+	if len(ms) == 0 {
+		return true
+	}
+
+	for _, m := range StrongestMetadata(ms) {
+		for _, d := range digests {
+			if d == m.Digest() {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// strongestAlgorithm returns the algorithm a browser would check an integrity attribute value with, or "" if it has
+// no valid metadata.
+func strongestAlgorithm(integrity string) string {
+	ms := StrongestMetadata(ParseMetadata(integrity))
+	if len(ms) == 0 {
+		return ""
+	}
+
+	return ms[0].Algorithm
+}
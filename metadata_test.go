@@ -0,0 +1,79 @@
+package sri
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMetadata(t *testing.T) {
+	type testCase struct {
+		integrity string
+		exp       []Metadata
+	}
+
+	testCases := []testCase{
+		{
+			integrity: "",
+			exp:       []Metadata{},
+		},
+		{
+			integrity: "sha256-abc=",
+			exp:       []Metadata{{Algorithm: "sha256", Value: "abc="}},
+		},
+		{
+			integrity: "  SHA384-abc\tsha512-def?foo=bar \n md5-ghi sha1-jkl notametadata ",
+			exp: []Metadata{
+				{Algorithm: "sha384", Value: "abc"},
+				{Algorithm: "sha512", Value: "def", Options: "foo=bar"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		if ms := ParseMetadata(tc.integrity); !reflect.DeepEqual(ms, tc.exp) {
+			t.Fatalf("Expected metadata %+v from %q. Got %+v", tc.exp, tc.integrity, ms)
+		}
+	}
+}
+
+func TestStrongestMetadata(t *testing.T) {
+	ms := ParseMetadata("sha256-a sha512-b sha384-c sha512-d")
+	exp := []Metadata{{Algorithm: "sha512", Value: "b"}, {Algorithm: "sha512", Value: "d"}}
+
+	if strongest := StrongestMetadata(ms); !reflect.DeepEqual(strongest, exp) {
+		t.Fatalf("Expected strongest metadata %+v. Got %+v", exp, strongest)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	g := &Generator{Hash: AllHashes}
+	fis, err := g.Generate([]string{"test/test.js"})
+	if err != nil {
+		t.Fatalf("Unexpected error from Generate call (target: 'test/test.js'). %q", err)
+	}
+
+	sha256 := "sha256-jEUM4jWrIiMerWo9zYrx6XwQ5eI77uzuETBptBvPlRQ="
+	sha384 := "sha384-zBTHeP/UZLYRhjvTi7r3Dx7MTCNf/ddGENI26AacmrgqzH8YOkA+EJ14MXpwD4wL"
+
+	type testCase struct {
+		integrity string
+		exp       bool
+	}
+
+	testCases := []testCase{
+		{"", true},
+		{"md5-unsupported", true},
+		{sha256, true},
+		{sha256 + "?some-option", true},
+		{"sha256-notthedigest", false},
+		{"sha256-notthedigest " + sha256, true},
+		{sha256 + " sha384-notthedigest", false},
+		{"sha256-notthedigest " + sha384, true},
+	}
+
+	for _, tc := range testCases {
+		if match := Match(tc.integrity, fis); match != tc.exp {
+			t.Fatalf("Expected match of %q to be %t", tc.integrity, tc.exp)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package sri
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Types of target. Generator.Type may be set to one of them to override how targets are classified.
+const (
+	SourceAuto    = "auto"
+	SourceURL     = "url"
+	SourceFile    = "file"
+	SourceDir     = "dir"
+	SourceArchive = "archive"
+	SourceData    = "data"
+	SourceStdin   = "stdin"
+)
+
+var sourceTypes = []string{SourceAuto, SourceURL, SourceFile, SourceDir, SourceArchive, SourceData, SourceStdin}
+
+// ValidateType returns an error if typ is not one of the Source types.
+func ValidateType(typ string) error {
+	for _, t := range sourceTypes {
+		if typ == t {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Invalid target type '%s'. Expected one of '%s'", typ, strings.Join(sourceTypes, "', '"))
+}
+
+// classify works out the type of a target, along with the location it should be read from; the path of file://
+// URLs, and the archive (and member) of 'archive!member' targets. Targets are classified by Type if it is set, or
+// otherwise by their scheme and what they are on disk; http(s) URLs, data: URIs, file:// URLs, paths (absolute or
+// relative) to files, directories and archives, and StdinTarget.
+func (g *Generator) classify(target string) (kind, location, member string, err error) {
+	typ := g.Type
+	if typ == "" {
+		typ = SourceAuto
+	}
+
+	if err := ValidateType(typ); err != nil {
+		return "", "", "", err
+	}
+
+	switch {
+	case typ == SourceStdin || typ == SourceAuto && target == StdinTarget:
+		if target != StdinTarget {
+			return "", "", "", fmt.Errorf("Target %s can't be read from stdin. Expected '%s'", target, StdinTarget)
+		}
+		return SourceStdin, target, "", nil
+	case typ == SourceURL:
+		if !isRemote(target) {
+			return "", "", "", fmt.Errorf("Target %s is not an http(s) URL", target)
+		}
+		return SourceURL, target, "", nil
+	case typ == SourceData:
+		if !isDataURI(target) {
+			return "", "", "", fmt.Errorf("Target %s is not a data: URI", target)
+		}
+		return SourceData, target, "", nil
+	}
+
+	location = target
+	if scheme := targetScheme(target); scheme != "" {
+		switch scheme {
+		case "http", "https":
+			if !isRemote(target) {
+				return "", "", "", fmt.Errorf("Target %s is not a valid URL. Expected a host", target)
+			}
+			if typ != SourceAuto {
+				return "", "", "", fmt.Errorf("Target %s is a URL, not a %s", target, typ)
+			}
+			return SourceURL, target, "", nil
+		case "data":
+			if typ != SourceAuto {
+				return "", "", "", fmt.Errorf("Target %s is a data: URI, not a %s", target, typ)
+			}
+			return SourceData, target, "", nil
+		case "file":
+			if location, err = filePath(target); err != nil {
+				return "", "", "", err
+			}
+		default:
+			return "", "", "", fmt.Errorf("Unsupported target %s. Expected an http(s), data: or file:// URL, a path or '%s'", target, StdinTarget)
+		}
+	}
+
+	if archive, member, ok := splitArchiveTarget(location); ok && (typ == SourceAuto || typ == SourceArchive) {
+		return SourceArchive, archive, member, nil
+	}
+
+	fi, err := os.Stat(location)
+	if os.IsNotExist(err) {
+		return "", "", "", fmt.Errorf("Target %s does not exist", target)
+	} else if err != nil {
+		return "", "", "", err
+	}
+
+	mode := fi.Mode()
+	switch {
+	case mode.IsDir():
+		kind = SourceDir
+	case mode.IsRegular() && archiveFormat(location) != "":
+		kind = SourceArchive
+	case mode.IsRegular(), mode&os.ModeNamedPipe != 0, mode&os.ModeCharDevice != 0:
+		// Named pipes and character devices, such as the /dev/fd/N of process substitution, are read like files
+		kind = SourceFile
+	default:
+		return "", "", "", fmt.Errorf("Unsupported target %s. Sockets and block devices can't be hashed", target)
+	}
+
+	switch {
+	case typ == SourceAuto, typ == kind:
+	case typ == SourceFile && kind == SourceArchive:
+		// Archives may be hashed as a whole, rather than by their files
+		kind = SourceFile
+	case typ == SourceArchive && archiveFormat(location) == "":
+		return "", "", "", fmt.Errorf("Target %s is not an archive. Expected a .zip, .tar, .tgz or .tar.gz file", target)
+	default:
+		return "", "", "", fmt.Errorf("Target %s is a %s, not a %s", target, kind, typ)
+	}
+
+	return kind, location, "", nil
+}
+
+// targetScheme returns the lower case scheme of a target, or "" if it has none. Single letter schemes are Windows
+// drive letters rather than schemes.
+func targetScheme(target string) string {
+	idx := strings.Index(target, ":")
+	if idx < 2 {
+		return ""
+	}
+
+	scheme := strings.ToLower(target[:idx])
+	for i, c := range scheme {
+		if !(c >= 'a' && c <= 'z' || i > 0 && (c >= '0' && c <= '9' || c == '+' || c == '-' || c == '.')) {
+			return ""
+		}
+	}
+
+	return scheme
+}
+
+// filePath returns the local path of a file:// URL.
+func filePath(target string) (string, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return "", fmt.Errorf("Target %s is not a valid file:// URL. %s", target, err)
+	}
+
+	if u.Host != "" && u.Host != "localhost" {
+		return "", fmt.Errorf("Unsupported target %s. Only local file:// URLs can be hashed", target)
+	}
+
+	if u.Path == "" {
+		return "", fmt.Errorf("Target %s is not a valid file:// URL. Expected a path", target)
+	}
+
+	return filepath.FromSlash(u.Path), nil
+}
+
+func isDataURI(target string) bool {
+	return targetScheme(target) == "data"
+}
+
+// decodeDataURI returns the content of a data: URI, either base64 or percent encoded.
+func decodeDataURI(target string) ([]byte, error) {
+	comma := strings.Index(target, ",")
+	if !isDataURI(target) || comma < 0 {
+		return nil, fmt.Errorf("Target %s is not a valid data: URI. Expected a ','", target)
+	}
+
+	meta, data := target[len("data:"):comma], target[comma+1:]
+	if strings.HasSuffix(strings.ToLower(meta), ";base64") {
+		b, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(data), ""))
+		if err != nil {
+			return nil, fmt.Errorf("Target %s is not a valid data: URI. %s", target, err)
+		}
+		return b, nil
+	}
+
+	s, err := url.PathUnescape(data)
+	if err != nil {
+		return nil, fmt.Errorf("Target %s is not a valid data: URI. %s", target, err)
+	}
+
+	return []byte(s), nil
+}
+
+// DataURI produces the integrities of the content of a data: URI.
+func (g *Generator) DataURI(target string) ([]Integrity, error) {
+	return g.data(context.Background(), target)
+}
+
+func (g *Generator) data(ctx context.Context, target string) ([]Integrity, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	b, err := decodeDataURI(target)
+	if err != nil {
+		return nil, err
+	}
+
+	fis, err := g.Integrities(target, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range fis {
+		fis[i].FileName = target
+	}
+
+	return fis, nil
+}
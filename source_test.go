@@ -0,0 +1,123 @@
+package sri
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	abs, err := filepath.Abs("test/test.js")
+	if err != nil {
+		t.Fatalf("Unable to find absolute path of test file. %q", err)
+	}
+
+	dir, err := ioutil.TempDir(".", "sri")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir. %q", err)
+	}
+	defer os.RemoveAll(dir)
+
+	empty := filepath.Join(dir, "empty.js")
+	if err := ioutil.WriteFile(empty, nil, 0644); err != nil {
+		t.Fatalf("Unable to write test file. %q", err)
+	}
+
+	archive := filepath.Join(dir, "bundle.tgz")
+	if err := ioutil.WriteFile(archive, []byte("not really a tgz"), 0644); err != nil {
+		t.Fatalf("Unable to write test file. %q", err)
+	}
+
+	fileURL := (&url.URL{Scheme: "file", Path: filepath.ToSlash(abs)}).String()
+
+	type testCase struct {
+		target   string
+		typ      string
+		kind     string
+		location string
+		errMsg   string
+	}
+
+	tcs := []testCase{
+		{target: "https://cdn.com/app.js", kind: SourceURL, location: "https://cdn.com/app.js"},
+		{target: "HTTP://cdn.com/app.js", kind: SourceURL, location: "HTTP://cdn.com/app.js"},
+		{target: "data:,alert(1)", kind: SourceData, location: "data:,alert(1)"},
+		{target: StdinTarget, kind: SourceStdin, location: StdinTarget},
+		{target: abs, kind: SourceFile, location: abs},
+		{target: "test/test.js", kind: SourceFile, location: "test/test.js"},
+		{target: fileURL, kind: SourceFile, location: abs},
+		{target: "test", kind: SourceDir, location: "test"},
+		{target: empty, kind: SourceFile, location: empty},
+		{target: archive, kind: SourceArchive, location: archive},
+		{target: archive, typ: SourceFile, kind: SourceFile, location: archive},
+		{target: "test/test.js", typ: SourceURL, errMsg: "is not an http(s) URL"},
+		{target: "test", typ: SourceFile, errMsg: "is a dir, not a file"},
+		{target: "test/test.js", typ: SourceArchive, errMsg: "is not an archive"},
+		{target: "test/not-real.js", errMsg: "does not exist"},
+		{target: "ftp://cdn.com/app.js", errMsg: "Unsupported target"},
+		{target: "https:///app.js", errMsg: "Expected a host"},
+		{target: "file://remote.com/app.js", errMsg: "Only local file:// URLs"},
+		{target: "test/test.js", typ: "zip", errMsg: "Invalid target type"},
+	}
+
+	for _, tc := range tcs {
+		g := &Generator{Type: tc.typ}
+		kind, location, _, err := g.classify(tc.target)
+
+		if tc.errMsg != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.errMsg) {
+				t.Fatalf("Expected %s to produce an error containing %q. Got %v", tc.target, tc.errMsg, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("Unexpected error classifying %s. %q", tc.target, err)
+		}
+
+		if kind != tc.kind || location != tc.location {
+			t.Fatalf("Expected %s to be a %s at %s. Got a %s at %s", tc.target, tc.kind, tc.location, kind, location)
+		}
+	}
+}
+
+func TestGenerateSources(t *testing.T) {
+	abs, err := filepath.Abs("test/test.js")
+	if err != nil {
+		t.Fatalf("Unable to find absolute path of test file. %q", err)
+	}
+
+	js, err := ioutil.ReadFile("test/test.js")
+	if err != nil {
+		t.Fatalf("Unable to read test file. %q", err)
+	}
+
+	dataURI := "data:text/javascript;base64," + base64.StdEncoding.EncodeToString(js)
+	emptyURI := "data:text/javascript,"
+
+	g := &Generator{Hash: SHA256}
+	fis, err := g.Generate([]string{abs, dataURI, emptyURI})
+	if err != nil {
+		t.Fatalf("Unexpected error from Generate call. %q", err)
+	}
+
+	exp := map[string]string{
+		"test.js": "sha256-jEUM4jWrIiMerWo9zYrx6XwQ5eI77uzuETBptBvPlRQ=",
+		dataURI:   "sha256-jEUM4jWrIiMerWo9zYrx6XwQ5eI77uzuETBptBvPlRQ=",
+		emptyURI:  "sha256-47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=",
+	}
+
+	if len(fis) != len(exp) {
+		t.Fatalf("Expected %d integrities. Got %d (%+v)", len(exp), len(fis), fis)
+	}
+
+	for _, fi := range fis {
+		if exp[fi.FileName] != fi.Digest {
+			t.Fatalf("Expected %s to have digest %s. Got %s", fi.FileName, exp[fi.FileName], fi.Digest)
+		}
+	}
+}
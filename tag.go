@@ -0,0 +1,261 @@
+package sri
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"path"
+	"strings"
+)
+
+const (
+	// CrossOriginNone omits the crossorigin attribute, even from tags referencing remote URLs.
+	CrossOriginNone = "none"
+
+	QuoteSingle = "single"
+	QuoteDouble = "double"
+)
+
+// TagOptions configures the attributes of rendered tags. Options which don't apply to stylesheets (type, nomodule,
+// async and defer) are ignored when rendering link tags.
+type TagOptions struct {
+	// CrossOrigin is the value of the crossorigin attribute; CrossOriginAnonymous, CrossOriginUseCredentials or
+	// CrossOriginNone. If empty, remote URLs are given CrossOriginAnonymous, as browsers require a crossorigin
+	// attribute to check the integrity of cross-origin resources, and local files are given none.
+	CrossOrigin    string `json:"crossorigin,omitempty"`
+	ReferrerPolicy string `json:"referrerpolicy,omitempty"`
+	Module         bool   `json:"module,omitempty"`
+	NoModule       bool   `json:"nomodule,omitempty"`
+	Async          bool   `json:"async,omitempty"`
+	Defer          bool   `json:"defer,omitempty"`
+
+	// Quote is the quote style of attribute values; QuoteSingle (the default) or QuoteDouble.
+	Quote string `json:"quote,omitempty"`
+
+	// XHTML self-closes link tags and gives boolean attributes a value, e.g async="async".
+	XHTML bool `json:"xhtml,omitempty"`
+}
+
+// TagRule applies its TagOptions, in place of the default options, to files matching Pattern. Patterns are matched
+// as they are by Generator.Include against the path of local files or the path of remote URLs.
+type TagRule struct {
+	Pattern string `json:"pattern"`
+	TagOptions
+}
+
+// TagRenderer renders script tags, or stylesheet link tags for CSS files, using the options of the first rule
+// matching each file, or the default options if none match.
+type TagRenderer struct {
+	Default TagOptions `json:"default"`
+	Rules   []TagRule  `json:"rules,omitempty"`
+}
+
+// Tag returns a script tag referencing source with the given digest, or a stylesheet link tag for CSS files, using
+// the default TagOptions.
+func Tag(source, digest string) string {
+	return (&TagRenderer{}).Render(source, digest)
+}
+
+// Render returns a tag referencing source with the given integrity attribute value.
+func (tr *TagRenderer) Render(source, integrity string) string {
+	opts := tr.options(source)
+	common := opts.attr("integrity", integrity) + opts.corsAttrs(source)
+
+	if sourceExt(source) == ".css" {
+		return "<link" + opts.attr("rel", "stylesheet") + opts.attr("href", source) + common + opts.linkClosing()
+	}
+
+	var b strings.Builder
+	b.WriteString("<script" + opts.attr("src", source) + common)
+	if opts.Module {
+		b.WriteString(opts.attr("type", "module"))
+	}
+	if opts.NoModule {
+		b.WriteString(opts.boolAttr("nomodule"))
+	}
+	if opts.Async {
+		b.WriteString(opts.boolAttr("async"))
+	}
+	if opts.Defer {
+		b.WriteString(opts.boolAttr("defer"))
+	}
+	b.WriteString("></script>")
+
+	return b.String()
+}
+
+// RenderPreload returns a link tag preloading source with the given integrity attribute value; a modulepreload
+// for module scripts, otherwise a preload as a script or style. Its crossorigin attribute matches the tag returned
+// by Render, as the browser will fetch the resource a second time if they differ.
+func (tr *TagRenderer) RenderPreload(source, integrity string) string {
+	opts := tr.options(source)
+
+	rel := opts.attr("rel", "preload") + opts.attr("as", preloadAs(source))
+	if opts.Module && sourceExt(source) != ".css" {
+		rel = opts.attr("rel", "modulepreload")
+	}
+
+	return "<link" + rel + opts.attr("href", source) + opts.attr("integrity", integrity) + opts.corsAttrs(source) +
+		opts.linkClosing()
+}
+
+// LinkHeader returns the value of a Link HTTP header preloading source with the given integrity, equivalent to the
+// tag returned by RenderPreload.
+func (tr *TagRenderer) LinkHeader(source, integrity string) string {
+	opts := tr.options(source)
+
+	params := []string{"<" + source + ">"}
+	if opts.Module && sourceExt(source) != ".css" {
+		params = append(params, "rel=modulepreload")
+	} else {
+		params = append(params, "rel=preload", "as="+preloadAs(source))
+	}
+
+	params = append(params, fmt.Sprintf("integrity=%q", integrity))
+	if crossOrigin := opts.crossOrigin(source); crossOrigin != "" {
+		params = append(params, "crossorigin="+crossOrigin)
+	}
+	if opts.ReferrerPolicy != "" {
+		params = append(params, "referrerpolicy="+opts.ReferrerPolicy)
+	}
+
+	return strings.Join(params, "; ")
+}
+
+func preloadAs(source string) string {
+	if sourceExt(source) == ".css" {
+		return "style"
+	}
+
+	return "script"
+}
+
+func (o TagOptions) attr(name, value string) string {
+	quote := "'"
+	if o.Quote == QuoteDouble {
+		quote = `"`
+	}
+
+	return fmt.Sprintf(` %s=%s%s%s`, name, quote, html.EscapeString(value), quote)
+}
+
+func (o TagOptions) boolAttr(name string) string {
+	if o.XHTML {
+		return o.attr(name, name)
+	}
+
+	return " " + name
+}
+
+// crossOrigin returns the crossorigin attribute value of tags referencing source, or "" if they have none.
+func (o TagOptions) crossOrigin(source string) string {
+	switch {
+	case o.CrossOrigin == CrossOriginNone:
+		return ""
+	case o.CrossOrigin == "" && isRemote(source):
+		return CrossOriginAnonymous
+	}
+
+	return o.CrossOrigin
+}
+
+// crossOrigin returns the crossorigin attribute value of tags referencing source, or "" if they have none, using the
+// tag options matching source and falling back to the CrossOrigin mode of the Generator.
+func (g *Generator) crossOrigin(source string) string {
+	opts := g.tags().options(source)
+	if opts.CrossOrigin == "" {
+		opts.CrossOrigin = g.CrossOrigin
+	}
+
+	return opts.crossOrigin(source)
+}
+
+// corsAttrs returns the crossorigin and referrerpolicy attributes of tags referencing source.
+func (o TagOptions) corsAttrs(source string) string {
+	var attrs string
+	if crossOrigin := o.crossOrigin(source); crossOrigin != "" {
+		attrs += o.attr("crossorigin", crossOrigin)
+	}
+
+	if o.ReferrerPolicy != "" {
+		attrs += o.attr("referrerpolicy", o.ReferrerPolicy)
+	}
+
+	return attrs
+}
+
+func (o TagOptions) linkClosing() string {
+	if o.XHTML {
+		return " />"
+	}
+
+	return ">"
+}
+
+// Validate returns an error if the default options or the options of any rule are invalid.
+func (tr *TagRenderer) Validate() error {
+	if err := tr.Default.validate(); err != nil {
+		return err
+	}
+
+	for _, r := range tr.Rules {
+		if r.Pattern == "" {
+			return fmt.Errorf("Tag rule has an empty pattern")
+		}
+
+		if err := r.TagOptions.validate(); err != nil {
+			return fmt.Errorf("Tag rule '%s' is invalid. %s", r.Pattern, err)
+		}
+	}
+
+	return nil
+}
+
+func (o TagOptions) validate() error {
+	switch o.CrossOrigin {
+	case "", CrossOriginAnonymous, CrossOriginUseCredentials, CrossOriginNone:
+	default:
+		return fmt.Errorf("Invalid crossorigin '%s'. Expected one of 'anonymous', 'use-credentials' or 'none'", o.CrossOrigin)
+	}
+
+	switch o.Quote {
+	case "", QuoteSingle, QuoteDouble:
+	default:
+		return fmt.Errorf("Invalid quote style '%s'. Expected one of 'single' or 'double'", o.Quote)
+	}
+
+	if o.Module && o.NoModule {
+		return fmt.Errorf("A tag can't be both a module and nomodule")
+	}
+
+	return nil
+}
+
+func (tr *TagRenderer) options(source string) TagOptions {
+	if len(tr.Rules) == 0 {
+		return tr.Default
+	}
+
+	p := source
+	if u, err := url.Parse(source); err == nil && isRemote(source) {
+		p = u.Path
+	}
+	p = strings.TrimPrefix(path.Clean(strings.Replace(p, "\\", "/", -1)), "/")
+
+	for _, r := range tr.Rules {
+		if matchGlob(r.Pattern, p) {
+			return r.TagOptions
+		}
+	}
+
+	return tr.Default
+}
+
+// sourceExt returns the extension of a file path, or of the path of a URL.
+func sourceExt(source string) string {
+	if u, err := url.Parse(source); err == nil && isRemote(source) {
+		return strings.ToLower(path.Ext(u.Path))
+	}
+
+	return strings.ToLower(path.Ext(source))
+}
@@ -0,0 +1,135 @@
+package sri
+
+import "testing"
+
+func TestTagRenderer(t *testing.T) {
+	type testCase struct {
+		tr     *TagRenderer
+		source string
+		exp    string
+	}
+
+	testCases := []testCase{
+		{
+			tr:     &TagRenderer{},
+			source: "js/app.js",
+			exp:    `<script src='js/app.js' integrity='sha256-abc'></script>`,
+		},
+		{
+			tr:     &TagRenderer{},
+			source: "https://cdn.com/app.css?v=1&t=2",
+			exp:    `<link rel='stylesheet' href='https://cdn.com/app.css?v=1&amp;t=2' integrity='sha256-abc' crossorigin='anonymous'>`,
+		},
+		{
+			tr:     &TagRenderer{Default: TagOptions{CrossOrigin: CrossOriginNone}},
+			source: "https://cdn.com/app.js",
+			exp:    `<script src='https://cdn.com/app.js' integrity='sha256-abc'></script>`,
+		},
+		{
+			tr: &TagRenderer{Default: TagOptions{
+				CrossOrigin:    CrossOriginUseCredentials,
+				ReferrerPolicy: "no-referrer",
+				Module:         true,
+				Async:          true,
+				Quote:          QuoteDouble,
+			}},
+			source: "js/app.js",
+			exp:    `<script src="js/app.js" integrity="sha256-abc" crossorigin="use-credentials" referrerpolicy="no-referrer" type="module" async></script>`,
+		},
+		{
+			tr:     &TagRenderer{Default: TagOptions{XHTML: true, NoModule: true, Defer: true}},
+			source: "js/legacy.js",
+			exp:    `<script src='js/legacy.js' integrity='sha256-abc' nomodule='nomodule' defer='defer'></script>`,
+		},
+		{
+			tr:     &TagRenderer{Default: TagOptions{XHTML: true, Async: true}},
+			source: "css/app.css",
+			exp:    `<link rel='stylesheet' href='css/app.css' integrity='sha256-abc' />`,
+		},
+		{
+			tr: &TagRenderer{
+				Default: TagOptions{Defer: true},
+				Rules: []TagRule{
+					{Pattern: "*.mjs", TagOptions: TagOptions{Module: true}},
+					{Pattern: "legacy/**", TagOptions: TagOptions{NoModule: true}},
+				},
+			},
+			source: "https://cdn.com/legacy/app.js",
+			exp:    `<script src='https://cdn.com/legacy/app.js' integrity='sha256-abc' crossorigin='anonymous' nomodule></script>`,
+		},
+		{
+			tr: &TagRenderer{
+				Default: TagOptions{Defer: true},
+				Rules:   []TagRule{{Pattern: "*.mjs", TagOptions: TagOptions{Module: true}}},
+			},
+			source: "./dist/app.js",
+			exp:    `<script src='./dist/app.js' integrity='sha256-abc' defer></script>`,
+		},
+	}
+
+	for _, tc := range testCases {
+		if tag := tc.tr.Render(tc.source, "sha256-abc"); tag != tc.exp {
+			t.Fatalf("Expected tag %s. Got %s", tc.exp, tag)
+		}
+	}
+}
+
+func TestTagRendererValidate(t *testing.T) {
+	invalid := []*TagRenderer{
+		{Default: TagOptions{CrossOrigin: "sometimes"}},
+		{Default: TagOptions{Quote: "backtick"}},
+		{Default: TagOptions{Module: true, NoModule: true}},
+		{Rules: []TagRule{{Pattern: ""}}},
+		{Rules: []TagRule{{Pattern: "*.js", TagOptions: TagOptions{Quote: "backtick"}}}},
+	}
+
+	for _, tr := range invalid {
+		if err := tr.Validate(); err == nil {
+			t.Fatalf("Expected an error validating %+v", tr)
+		}
+	}
+
+	if err := (&TagRenderer{Rules: []TagRule{{Pattern: "*.mjs", TagOptions: TagOptions{Module: true}}}}).Validate(); err != nil {
+		t.Fatalf("Unexpected error from Validate call. %q", err)
+	}
+}
+
+func TestTagRendererPreload(t *testing.T) {
+	type testCase struct {
+		tr      *TagRenderer
+		source  string
+		preload string
+		link    string
+	}
+
+	testCases := []testCase{
+		{
+			tr:      &TagRenderer{},
+			source:  "https://cdn.com/app.js",
+			preload: `<link rel='preload' as='script' href='https://cdn.com/app.js' integrity='sha256-abc' crossorigin='anonymous'>`,
+			link:    `<https://cdn.com/app.js>; rel=preload; as=script; integrity="sha256-abc"; crossorigin=anonymous`,
+		},
+		{
+			tr:      &TagRenderer{Default: TagOptions{Module: true, Quote: QuoteDouble, ReferrerPolicy: "no-referrer"}},
+			source:  "js/app.mjs",
+			preload: `<link rel="modulepreload" href="js/app.mjs" integrity="sha256-abc" referrerpolicy="no-referrer">`,
+			link:    `<js/app.mjs>; rel=modulepreload; integrity="sha256-abc"; referrerpolicy=no-referrer`,
+		},
+		{
+			tr:      &TagRenderer{Default: TagOptions{Module: true, XHTML: true, CrossOrigin: CrossOriginUseCredentials}},
+			source:  "css/app.css",
+			preload: `<link rel='preload' as='style' href='css/app.css' integrity='sha256-abc' crossorigin='use-credentials' />`,
+			link:    `<css/app.css>; rel=preload; as=style; integrity="sha256-abc"; crossorigin=use-credentials`,
+		},
+	}
+
+	for _, tc := range testCases {
+		if preload := tc.tr.RenderPreload(tc.source, "sha256-abc"); preload != tc.preload {
+			t.Fatalf("Expected preload %s. Got %s", tc.preload, preload)
+		}
+
+		if link := tc.tr.LinkHeader(tc.source, "sha256-abc"); link != tc.link {
+			t.Fatalf("Expected Link header %s. Got %s", tc.link, link)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package sri
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// TemplateFile is the data tag templates are executed with for each file, and output templates for every file.
+type TemplateFile struct {
+	// Path identifies the file, as the FileName of its integrities.
+	Path string
+
+	// Source is the path or URL the file was read from, and URL is the URL tags should reference.
+	Source string
+	URL    string
+
+	// Ext is the lower-cased extension of the file, e.g '.js', and IsCSS reports whether it is a stylesheet.
+	Ext   string
+	IsCSS bool
+
+	// Digests maps each algorithm to the digest of the file, e.g Digests.sha384.
+	Digests map[string]string
+
+	// Integrity is the integrity attribute value; the digest of the tag being rendered by a tag template, or every
+	// digest of the file for output templates.
+	Integrity string
+
+	Size int64
+
+	// CrossOrigin is the crossorigin attribute value of the tag, or "" if it should have none, as it is for generated
+	// tags. The builtin templates render it in place of the crossorigin attribute of generated tags.
+	CrossOrigin string
+
+	// Tag, Preload and Link are the rendered tags and Link header value of the file carrying every digest of the file,
+	// as Integrity does, available to output templates.
+	Tag     string
+	Preload string
+	Link    string
+}
+
+// TemplateOutput is the data output templates are executed with.
+type TemplateOutput struct {
+	Files       []TemplateFile
+	Integrities []Integrity
+}
+
+// BuiltinTagTemplates are tag templates for common frameworks, by name.
+var BuiltinTagTemplates = map[string]string{
+	"html": `{{if .IsCSS}}<link rel="stylesheet" href="{{.URL}}" integrity="{{.Integrity}}"{{with .CrossOrigin}} crossorigin="{{.}}"{{end}}>` +
+		`{{else}}<script src="{{.URL}}" integrity="{{.Integrity}}"{{with .CrossOrigin}} crossorigin="{{.}}"{{end}}></script>{{end}}`,
+
+	"jinja": `{{if .IsCSS}}<link rel="stylesheet" href="{{"{{"}} url_for('static', filename='{{.Path}}') {{"}}"}}" integrity="{{.Integrity}}"{{with .CrossOrigin}} crossorigin="{{.}}"{{end}}>` +
+		`{{else}}<script src="{{"{{"}} url_for('static', filename='{{.Path}}') {{"}}"}}" integrity="{{.Integrity}}"{{with .CrossOrigin}} crossorigin="{{.}}"{{end}}></script>{{end}}`,
+
+	"erb": `{{if .IsCSS}}<%= stylesheet_link_tag "{{.Path}}", integrity: "{{.Integrity}}"{{with .CrossOrigin}}, crossorigin: "{{.}}"{{end}} %>` +
+		`{{else}}<%= javascript_include_tag "{{.Path}}", integrity: "{{.Integrity}}"{{with .CrossOrigin}}, crossorigin: "{{.}}"{{end}} %>{{end}}`,
+
+	"jsx": `{{if .IsCSS}}<link rel="stylesheet" href="{{.URL}}" integrity="{{.Integrity}}"{{with .CrossOrigin}} crossOrigin="{{.}}"{{end}} />` +
+		`{{else}}<script src="{{.URL}}" integrity="{{.Integrity}}"{{with .CrossOrigin}} crossOrigin="{{.}}"{{end}} />{{end}}`,
+
+	"gotemplate": `{{if .IsCSS}}<link rel="stylesheet" href="{{"{{"}} .StaticURL {{"}}"}}/{{.Path}}" integrity="{{.Integrity}}"{{with .CrossOrigin}} crossorigin="{{.}}"{{end}}>` +
+		`{{else}}<script src="{{"{{"}} .StaticURL {{"}}"}}/{{.Path}}" integrity="{{.Integrity}}"{{with .CrossOrigin}} crossorigin="{{.}}"{{end}}></script>{{end}}`,
+}
+
+// ParseTemplate parses a template from the named builtin tag template, or from the file at the given path if no
+// builtin has that name.
+func ParseTemplate(nameOrPath string) (*template.Template, error) {
+	if text, ok := BuiltinTagTemplates[nameOrPath]; ok {
+		return template.New(nameOrPath).Parse(text)
+	}
+
+	b, err := ioutil.ReadFile(nameOrPath)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read template '%s'. Expected a file or one of %s", nameOrPath, builtinNames())
+	}
+
+	return template.New(nameOrPath).Parse(string(b))
+}
+
+// TemplateFiles groups integrities by file name, sorted by file name. The tags of each file are rendered with every
+// digest of the file, so they agree with its Integrity.
+func (g *Generator) TemplateFiles(fis []Integrity) ([]TemplateFile, error) {
+	files := []TemplateFile{}
+	for _, group := range groupByFileName(fis) {
+		digests := make([]string, len(group))
+		for i, fi := range group {
+			digests[i] = fi.Digest
+		}
+		integrity := strings.Join(digests, " ")
+
+		combined := append([]Integrity{}, group...)
+		for i := range combined {
+			combined[i].Integrity = integrity
+		}
+
+		if err := g.renderTags(combined); err != nil {
+			return nil, err
+		}
+
+		f := g.newTemplateFile(group, integrity)
+		f.Tag, f.Preload, f.Link = combined[0].Tag, combined[0].Preload, combined[0].Link
+		files = append(files, f)
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+
+	return files, nil
+}
+
+// WriteTemplate executes an output template with every file of the given integrities.
+func (g *Generator) WriteTemplate(w io.Writer, t *template.Template, fis []Integrity) error {
+	files, err := g.TemplateFiles(fis)
+	if err != nil {
+		return err
+	}
+
+	return t.Execute(w, TemplateOutput{Files: files, Integrities: fis})
+}
+
+// newTemplateFile builds the TemplateFile of the integrities of a single file.
+func (g *Generator) newTemplateFile(fis []Integrity, integrity string) TemplateFile {
+	fi := fis[0]
+	f := TemplateFile{
+		Path:        fi.FileName,
+		Source:      fi.localSource(),
+		URL:         fi.Source,
+		Ext:         sourceExt(fi.Source),
+		Digests:     make(map[string]string),
+		Integrity:   integrity,
+		Size:        fi.Size,
+		CrossOrigin: g.crossOrigin(fi.Source),
+	}
+	f.IsCSS = f.Ext == ".css"
+
+	for _, fi := range fis {
+		for _, digest := range strings.Fields(fi.Digest) {
+			f.Digests[digestAlgorithm(digest)] = digest
+		}
+	}
+
+	return f
+}
+
+// groupByFileName groups integrities by file name, whether or not those of each file are adjacent.
+func groupByFileName(fis []Integrity) [][]Integrity {
+	byName := make(map[string][]Integrity)
+	names := []string{}
+	for _, fi := range fis {
+		if _, ok := byName[fi.FileName]; !ok {
+			names = append(names, fi.FileName)
+		}
+		byName[fi.FileName] = append(byName[fi.FileName], fi)
+	}
+
+	groups := make([][]Integrity, len(names))
+	for i, name := range names {
+		groups[i] = byName[name]
+	}
+
+	return groups
+}
+
+func builtinNames() string {
+	names := []string{}
+	for name := range BuiltinTagTemplates {
+		names = append(names, "'"+name+"'")
+	}
+	sort.Strings(names)
+
+	return strings.Join(names, ", ")
+}
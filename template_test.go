@@ -0,0 +1,109 @@
+package sri
+
+import (
+	"bytes"
+	"testing"
+	"text/template"
+)
+
+func TestTagTemplate(t *testing.T) {
+	type testCase struct {
+		name        string
+		target      string
+		crossOrigin string
+		exp         string
+	}
+
+	testCases := []testCase{
+		{
+			name:   "erb",
+			target: "test/test.js",
+			exp:    `<%= javascript_include_tag "test.js", integrity: "sha256-jEUM4jWrIiMerWo9zYrx6XwQ5eI77uzuETBptBvPlRQ=" %>`,
+		},
+		{
+			name:        "html",
+			target:      "test/test.js",
+			crossOrigin: CrossOriginNone,
+			exp:         `<script src="test/test.js" integrity="sha256-jEUM4jWrIiMerWo9zYrx6XwQ5eI77uzuETBptBvPlRQ="></script>`,
+		},
+		{
+			name:        "html",
+			target:      "test/test.js",
+			crossOrigin: CrossOriginUseCredentials,
+			exp:         `<script src="test/test.js" integrity="sha256-jEUM4jWrIiMerWo9zYrx6XwQ5eI77uzuETBptBvPlRQ=" crossorigin="use-credentials"></script>`,
+		},
+		{
+			name:   "jsx",
+			target: "./test",
+			exp:    `<link rel="stylesheet" href="test/test.css" integrity="sha256-ckxnbs3D4win9ik/Eh1/55cPi1yJ4xBVTU5npga+uw8=" />`,
+		},
+		{
+			name:        "jinja",
+			target:      "./test",
+			crossOrigin: CrossOriginAnonymous,
+			exp:         `<script src="{{ url_for('static', filename='test.js') }}" integrity="sha256-jEUM4jWrIiMerWo9zYrx6XwQ5eI77uzuETBptBvPlRQ=" crossorigin="anonymous"></script>`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tmpl, err := ParseTemplate(tc.name)
+		if err != nil {
+			t.Fatalf("Unexpected error parsing builtin template %s. %q", tc.name, err)
+		}
+
+		g := &Generator{Hash: SHA256, TagTemplate: tmpl, Tags: &TagRenderer{Default: TagOptions{CrossOrigin: tc.crossOrigin}}}
+		fis, err := g.Generate([]string{tc.target})
+		if err != nil {
+			t.Fatalf("Unexpected error from Generate call (target: %s). %q", tc.target, err)
+		}
+
+		found := false
+		for _, fi := range fis {
+			found = found || fi.Tag == tc.exp
+		}
+
+		if !found {
+			t.Fatalf("Expected a %s tag of %s. Got %+v", tc.name, tc.exp, fis)
+		}
+	}
+
+	if _, err := ParseTemplate("not-a-builtin-or-file"); err == nil {
+		t.Fatalf("Expected an error parsing a missing template")
+	}
+}
+
+func TestWriteTemplate(t *testing.T) {
+	g := &Generator{Hash: "sha256,sha512"}
+	fis, err := g.Generate([]string{"test/test.js", "test/test.css"})
+	if err != nil {
+		t.Fatalf("Unexpected error from Generate call. %q", err)
+	}
+
+	tmpl := template.Must(template.New("out").Parse(`{{range .Files}}{{.Path}} {{.Size}} {{.Digests.sha256}}
+{{end}}`))
+
+	var buf bytes.Buffer
+	if err := g.WriteTemplate(&buf, tmpl, fis); err != nil {
+		t.Fatalf("Unexpected error from WriteTemplate call. %q", err)
+	}
+
+	exp := `test.css 30 sha256-ckxnbs3D4win9ik/Eh1/55cPi1yJ4xBVTU5npga+uw8=
+test.js 76 sha256-jEUM4jWrIiMerWo9zYrx6XwQ5eI77uzuETBptBvPlRQ=
+`
+	if buf.String() != exp {
+		t.Fatalf("Expected output:\n%s\nGot:\n%s", exp, buf.String())
+	}
+
+	files, err := g.TemplateFiles(fis)
+	if err != nil {
+		t.Fatalf("Unexpected error from TemplateFiles call. %q", err)
+	}
+
+	if files[1].Integrity != files[1].Digests["sha256"]+" "+files[1].Digests["sha512"] {
+		t.Fatalf("Expected the integrity of a file to include every digest. Got %s", files[1].Integrity)
+	}
+
+	if expTag := Tag("test/test.js", files[1].Integrity); files[1].Tag != expTag {
+		t.Fatalf("Expected the tag of a file to carry its integrity %s. Got %s", expTag, files[1].Tag)
+	}
+}
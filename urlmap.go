@@ -0,0 +1,163 @@
+package sri
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+)
+
+// URLMap maps local files to the public URLs they are served from, so their digests can be produced from a local
+// build while their tags reference where browsers will load them.
+type URLMap struct {
+	// BaseURL is joined with the file name of each file not matched by a rewrite, e.g 'https://cdn.com/static/'
+	// and 'js/app.js' become 'https://cdn.com/static/js/app.js'.
+	BaseURL string
+
+	// Rewrites replace a prefix of the local path of files, as they were read (e.g 'dist/js/app.js'). The first
+	// matching rewrite is used.
+	Rewrites []URLRewrite
+}
+
+// URLRewrite replaces the prefix From of a local path with To.
+type URLRewrite struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Environment is the public URL and tags of a file in one of the Environments of a Generator.
+type Environment struct {
+	Source  string `json:"source"`
+	Tag     string `json:"tag"`
+	Preload string `json:"preload,omitempty"`
+	Link    string `json:"link,omitempty"`
+}
+
+// ParseURLRewrite parses a rewrite of the form 'from=to', e.g 'dist/=https://cdn.com/static/'.
+func ParseURLRewrite(s string) (URLRewrite, error) {
+	parts := strings.SplitN(s, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return URLRewrite{}, fmt.Errorf("Expected rewrite of the form 'from=to'. Got '%s'", s)
+	}
+
+	return URLRewrite{From: parts[0], To: parts[1]}, nil
+}
+
+// URL returns the public URL of a file, given the path BaseURL is joined with (its path relative to the directory it
+// was found in, or to the working directory) and the local path it was read from. Rewrites match whole path segments,
+// so 'dist' matches 'dist/app.js' but not 'distribution/app.js'. It is not ok if the file matches no rewrite and
+// there is no BaseURL.
+func (m *URLMap) URL(rel, source string) (string, bool) {
+	local := path.Clean(strings.Replace(source, "\\", "/", -1))
+	for _, r := range m.Rewrites {
+		from := path.Clean(strings.Replace(r.From, "\\", "/", -1))
+
+		rest := ""
+		switch {
+		case from == ".":
+			rest = local
+		case local == from:
+		case strings.HasPrefix(local, from+"/"):
+			rest = local[len(from)+1:]
+		default:
+			continue
+		}
+
+		if rest == "" || strings.HasSuffix(r.To, "/") {
+			return r.To + rest, true
+		}
+
+		return r.To + "/" + rest, true
+	}
+
+	if m.BaseURL == "" {
+		return "", false
+	}
+
+	return strings.TrimSuffix(m.BaseURL, "/") + "/" + (&url.URL{Path: rel}).EscapedPath(), true
+}
+
+// mapURLs records the tags of each file in every environment, then sets the public URL of each file as its Source,
+// rendering its tags again to reference it. rel is the path base URLs are joined with, or "" for the file name.
+func (g *Generator) mapURLs(fis []Integrity, rel string) error {
+	for _, group := range groupByFile(fis) {
+		key := rel
+		if key == "" {
+			key = group[0].FileName
+		}
+
+		if err := g.renderEnvironments(group, key); err != nil {
+			return err
+		}
+
+		if g.URLs == nil {
+			continue
+		}
+
+		u, ok := g.URLs.URL(key, group[0].Source)
+		if !ok {
+			continue
+		}
+
+		for i := range group {
+			group[i].Path, group[i].Source = group[i].Source, u
+		}
+
+		if err := g.renderTags(group); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// renderEnvironments sets the Environments of the integrities of a single file, rendering its tags with the public
+// URL of each environment.
+func (g *Generator) renderEnvironments(fis []Integrity, rel string) error {
+	if len(g.Environments) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(g.Environments))
+	for name := range g.Environments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for i := range fis {
+		fis[i].Environments = make(map[string]Environment)
+	}
+
+	for _, name := range names {
+		env := append([]Integrity{}, fis...)
+		if u, ok := g.Environments[name].URL(rel, fis[0].Source); ok {
+			for i := range env {
+				env[i].Source = u
+			}
+		}
+
+		if err := g.renderTags(env); err != nil {
+			return err
+		}
+
+		for i, fi := range env {
+			fis[i].Environments[name] = Environment{Source: fi.Source, Tag: fi.Tag, Preload: fi.Preload, Link: fi.Link}
+		}
+	}
+
+	return nil
+}
+
+// groupByFile splits integrities into the runs belonging to each file.
+func groupByFile(fis []Integrity) [][]Integrity {
+	groups := [][]Integrity{}
+	for start, i := 0, 1; i <= len(fis); i++ {
+		if i == len(fis) || fis[i].FileName != fis[start].FileName {
+			groups = append(groups, fis[start:i])
+			start = i
+		}
+	}
+
+	return groups
+}
@@ -0,0 +1,102 @@
+package sri
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestURLMap(t *testing.T) {
+	type testCase struct {
+		m        URLMap
+		fileName string
+		source   string
+		exp      string
+		ok       bool
+	}
+
+	rewrites := []URLRewrite{{From: "dist/vendor/", To: "https://vendor.com/"}, {From: "./dist/", To: "/static/"}}
+
+	tcs := map[string]testCase{
+		"base url":             {URLMap{BaseURL: "https://cdn.com/static/"}, "js/app.js", "dist/js/app.js", "https://cdn.com/static/js/app.js", true},
+		"base url, no slash":   {URLMap{BaseURL: "https://cdn.com"}, "app.js", "app.js", "https://cdn.com/app.js", true},
+		"escaped":              {URLMap{BaseURL: "https://cdn.com/"}, "my app.js", "my app.js", "https://cdn.com/my%20app.js", true},
+		"first rewrite":        {URLMap{Rewrites: rewrites}, "vendor/lib.js", "dist/vendor/lib.js", "https://vendor.com/lib.js", true},
+		"second rewrite":       {URLMap{Rewrites: rewrites}, "js/app.js", "dist/js/app.js", "/static/js/app.js", true},
+		"rewrite before base":  {URLMap{BaseURL: "https://cdn.com/", Rewrites: rewrites}, "js/app.js", "dist/js/app.js", "/static/js/app.js", true},
+		"base after rewrites":  {URLMap{BaseURL: "https://cdn.com/", Rewrites: rewrites}, "app.js", "src/app.js", "https://cdn.com/app.js", true},
+		"no matching rewrites": {URLMap{Rewrites: rewrites}, "app.js", "src/app.js", "", false},
+		"whole segments":       {URLMap{Rewrites: []URLRewrite{{From: "dist", To: "/static"}}}, "x.js", "distribution/x.js", "", false},
+		"no trailing slash":    {URLMap{Rewrites: []URLRewrite{{From: "dist", To: "/static"}}}, "js/x.js", "dist/js/x.js", "/static/js/x.js", true},
+	}
+
+	for name, tc := range tcs {
+		got, ok := tc.m.URL(tc.fileName, tc.source)
+		if got != tc.exp || ok != tc.ok {
+			t.Fatalf("%s: Expected %q (%t). Got %q (%t)", name, tc.exp, tc.ok, got, ok)
+		}
+	}
+
+	if _, err := ParseURLRewrite("no-equals"); err == nil {
+		t.Fatalf("Expected an error parsing a rewrite without '='")
+	}
+}
+
+func TestGenerateURLs(t *testing.T) {
+	g := &Generator{
+		Hash: SHA256,
+		URLs: &URLMap{BaseURL: "https://cdn.com/static/"},
+		Environments: map[string]*URLMap{
+			"staging": {BaseURL: "https://staging.cdn.com/"},
+		},
+	}
+
+	fis, err := g.Generate([]string{"test/test.js"})
+	if err != nil {
+		t.Fatalf("Unexpected error from Generate call. %q", err)
+	}
+
+	fi := fis[0]
+	expTag := "<script src='https://cdn.com/static/test/test.js' integrity='sha256-jEUM4jWrIiMerWo9zYrx6XwQ5eI77uzuETBptBvPlRQ=' crossorigin='anonymous'></script>"
+	if fi.FileName != "test.js" || fi.Source != "https://cdn.com/static/test/test.js" || fi.Path != "test/test.js" || fi.Tag != expTag {
+		t.Fatalf("Expected test.js to be tagged with its public URL. Got %+v", fi)
+	}
+
+	env := fi.Environments["staging"]
+	expEnvTag := "<script src='https://staging.cdn.com/test/test.js' integrity='sha256-jEUM4jWrIiMerWo9zYrx6XwQ5eI77uzuETBptBvPlRQ=' crossorigin='anonymous'></script>"
+	if env.Source != "https://staging.cdn.com/test/test.js" || env.Tag != expEnvTag {
+		t.Fatalf("Expected test.js to be tagged with its staging URL. Got %+v", env)
+	}
+
+	fis, err = g.Generate([]string{"./test"})
+	if err != nil {
+		t.Fatalf("Unexpected error from Generate call. %q", err)
+	}
+
+	for _, fi := range fis {
+		if fi.FileName == "test.js" && fi.Source != "https://cdn.com/static/test.js" {
+			t.Fatalf("Expected test.js to be joined with the base URL relative to the walked directory. Got %+v", fi)
+		}
+	}
+}
+
+func TestVerifyURLs(t *testing.T) {
+	g := &Generator{Hash: SHA256, URLs: &URLMap{BaseURL: "https://cdn.example.invalid/static/"}}
+	fis, err := g.Generate([]string{"./test"})
+	if err != nil {
+		t.Fatalf("Unexpected error from Generate call. %q", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteManifest(&buf, fis); err != nil {
+		t.Fatalf("Unexpected error from WriteManifest call. %q", err)
+	}
+
+	m, err := ReadManifest(&buf)
+	if err != nil {
+		t.Fatalf("Unexpected error from ReadManifest call. %q", err)
+	}
+
+	if result := (&Generator{Hash: SHA256}).VerifySources(m, "."); !result.OK() || len(result.Errors) > 0 {
+		t.Fatalf("Expected the local files of a manifest with public URLs to verify. Got %+v", result)
+	}
+}
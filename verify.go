@@ -0,0 +1,165 @@
+package sri
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// VerifyResult reports how the files in a Manifest differ from their current contents.
+type VerifyResult struct {
+	Drifted []string          `json:"drifted"`
+	Missing []string          `json:"missing"`
+	New     []string          `json:"new"`
+	Errors  map[string]string `json:"errors,omitempty"`
+}
+
+// OK reports whether every file in the Manifest still matches, with none missing and none added.
+func (r VerifyResult) OK() bool {
+	return len(r.Drifted) == 0 && len(r.Missing) == 0 && len(r.New) == 0
+}
+
+// ReadManifest decodes a Manifest previously written by WriteManifest.
+func ReadManifest(r io.Reader) (Manifest, error) {
+	var m Manifest
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, fmt.Errorf("Unable to parse manifest. %s", err)
+	}
+
+	return m, nil
+}
+
+// Sources returns the source to re-hash for every file in the Manifest, keyed by file name; the local path of files
+// recorded by their public URL. Relative paths of local files are resolved against root.
+func (m Manifest) Sources(root string) map[string]string {
+	sources := make(map[string]string)
+	for name, algos := range m {
+		source := name
+		for _, e := range algos {
+			if e.Path != "" {
+				source = e.Path
+				break
+			} else if e.Source != "" {
+				source = e.Source
+				break
+			}
+		}
+
+		if !isRemote(source) && !filepath.IsAbs(source) {
+			source = filepath.Join(root, filepath.FromSlash(source))
+		}
+
+		sources[name] = source
+	}
+
+	return sources
+}
+
+// Verify re-hashes each target and compares the results against m. Targets which can't be hashed are recorded in
+// the Errors of the result, and any files they were expected to produce are reported as missing.
+func (g *Generator) Verify(m Manifest, targets []string) VerifyResult {
+	errs := make(map[string]string)
+
+	var fis []Integrity
+	for _, target := range targets {
+		tfis, err := g.Generate([]string{target})
+		if err != nil {
+			errs[target] = err.Error()
+			continue
+		}
+
+		fis = append(fis, tfis...)
+	}
+
+	return diffManifest(m, NewManifest(fis), errs)
+}
+
+// VerifySources re-hashes the source of every file in m, resolving local files against root, and compares the
+// results against m. As only the files in the Manifest are hashed, none will be reported as new.
+func (g *Generator) VerifySources(m Manifest, root string) VerifyResult {
+	errs := make(map[string]string)
+
+	var fis []Integrity
+	for name, source := range m.Sources(root) {
+		sfis, err := g.Generate([]string{source})
+		if err != nil {
+			errs[source] = err.Error()
+			continue
+		}
+
+		for i := range sfis {
+			sfis[i].FileName = name
+		}
+
+		fis = append(fis, sfis...)
+	}
+
+	return diffManifest(m, NewManifest(fis), errs)
+}
+
+func diffManifest(expected, current Manifest, errs map[string]string) VerifyResult {
+	result := VerifyResult{Drifted: []string{}, Missing: []string{}, New: []string{}}
+	if len(errs) > 0 {
+		result.Errors = errs
+	}
+
+	for name, algos := range expected {
+		currentAlgos, ok := current[name]
+		if !ok {
+			result.Missing = append(result.Missing, name)
+			continue
+		}
+
+		if !algosMatch(algos, currentAlgos) {
+			result.Drifted = append(result.Drifted, name)
+		}
+	}
+
+	for name := range current {
+		if _, ok := expected[name]; !ok {
+			result.New = append(result.New, name)
+		}
+	}
+
+	sort.Strings(result.Drifted)
+	sort.Strings(result.Missing)
+	sort.Strings(result.New)
+
+	return result
+}
+
+// algosMatch reports whether every algorithm shared by both sets of entries has the same digest, splitting the
+// digests of combined entries by algorithm. Entries with no algorithms in common can't be shown to match.
+func algosMatch(expected, current map[string]ManifestEntry) bool {
+	currentDigests := entryDigests(current)
+
+	shared := 0
+	for algo, digest := range entryDigests(expected) {
+		c, ok := currentDigests[algo]
+		if !ok {
+			continue
+		}
+
+		if c != digest {
+			return false
+		}
+		shared++
+	}
+
+	return shared > 0
+}
+
+// entryDigests maps each algorithm of the entries of a single file to its digest.
+func entryDigests(entries map[string]ManifestEntry) map[string]string {
+	digests := make(map[string]string)
+	for _, e := range entries {
+		for _, digest := range strings.Fields(e.Digest) {
+			digests[digestAlgorithm(digest)] = digest
+		}
+	}
+
+	return digests
+}
@@ -0,0 +1,94 @@
+package sri
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	g := &Generator{Hash: AllHashes}
+	fis, err := g.Generate([]string{"./test"})
+	if err != nil {
+		t.Fatalf("Unexpected error from Generate call (target: './test'). %q", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteManifest(&buf, fis); err != nil {
+		t.Fatalf("Unexpected error from WriteManifest call. %q", err)
+	}
+
+	m, err := ReadManifest(&buf)
+	if err != nil {
+		t.Fatalf("Unexpected error from ReadManifest call. %q", err)
+	}
+
+	if result := g.Verify(m, []string{"./test"}); !result.OK() {
+		t.Fatalf("Expected unchanged manifest to verify. Got %+v", result)
+	}
+
+	drifted := m["test.js"]["sha384"]
+	drifted.Digest = "sha384-notthedigest"
+	m["test.js"]["sha384"] = drifted
+	delete(m, "test.css")
+	m["gone.js"] = map[string]ManifestEntry{"sha256": ManifestEntry{Digest: "sha256-gone"}}
+
+	result := g.Verify(m, []string{"./test"})
+	if result.OK() {
+		t.Fatalf("Expected modified manifest to fail verification")
+	}
+
+	if !reflect.DeepEqual(result.Drifted, []string{"test.js"}) {
+		t.Fatalf("Expected test.js to have drifted. Got %q", result.Drifted)
+	}
+
+	if !reflect.DeepEqual(result.Missing, []string{"gone.js"}) {
+		t.Fatalf("Expected gone.js to be missing. Got %q", result.Missing)
+	}
+
+	if !reflect.DeepEqual(result.New, []string{"test.css"}) {
+		t.Fatalf("Expected test.css to be new. Got %q", result.New)
+	}
+}
+
+func TestVerifySources(t *testing.T) {
+	g := &Generator{Hash: SHA256}
+	fis, err := g.Generate([]string{"./test"})
+	if err != nil {
+		t.Fatalf("Unexpected error from Generate call (target: './test'). %q", err)
+	}
+
+	m := NewManifest(fis)
+	if result := g.VerifySources(m, "."); !result.OK() {
+		t.Fatalf("Expected unchanged manifest to verify. Got %+v", result)
+	}
+
+	m["gone.js"] = map[string]ManifestEntry{"sha256": ManifestEntry{Digest: "sha256-gone", Source: "test/gone.js"}}
+	result := g.VerifySources(m, ".")
+
+	if !reflect.DeepEqual(result.Missing, []string{"gone.js"}) {
+		t.Fatalf("Expected gone.js to be missing. Got %q", result.Missing)
+	}
+
+	if _, ok := result.Errors["test/gone.js"]; !ok {
+		t.Fatalf("Expected an error hashing test/gone.js. Got %q", result.Errors)
+	}
+}
+
+func TestManifestSources(t *testing.T) {
+	m := Manifest{
+		"js/app.js": {"sha256": ManifestEntry{Digest: "sha256-a", Source: "dist/js/app.js"}},
+		"https://cdn.com/jquery.js": {
+			"sha256": ManifestEntry{Digest: "sha256-b", Source: "https://cdn.com/jquery.js"},
+		},
+	}
+
+	exp := map[string]string{
+		"js/app.js":                 "build/dist/js/app.js",
+		"https://cdn.com/jquery.js": "https://cdn.com/jquery.js",
+	}
+
+	if sources := m.Sources("build"); !reflect.DeepEqual(sources, exp) {
+		t.Fatalf("Expected sources %q. Got %q", exp, sources)
+	}
+}
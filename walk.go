@@ -0,0 +1,192 @@
+package sri
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	SymlinksSkip   = "skip"
+	SymlinksFollow = "follow"
+	SymlinksError  = "error"
+
+	// IgnoreFile is the name of the file listing exclude patterns for a directory, in the root of that directory.
+	IgnoreFile = ".sriignore"
+)
+
+// walkedFile is a regular file found while walking a directory target.
+type walkedFile struct {
+	Path string // Path to the file, including the directory target
+	Rel  string // Slash separated path of the file relative to the directory target
+}
+
+// walk recursively lists the files of dir which pass the include and exclude patterns of the Generator, along with
+// any patterns found in the directory's IgnoreFile.
+func (g *Generator) walk(dir string) ([]walkedFile, error) {
+	switch g.Symlinks {
+	case "", SymlinksSkip, SymlinksFollow, SymlinksError:
+	default:
+		return nil, fmt.Errorf("Invalid symlink policy '%s'. Expected one of 'skip', 'follow' or 'error'", g.Symlinks)
+	}
+
+	ignored, err := readIgnoreFile(filepath.Join(dir, IgnoreFile))
+	if err != nil {
+		return nil, err
+	}
+
+	w := &walker{
+		include: g.Include,
+		exclude: append(append([]string{IgnoreFile}, g.Exclude...), ignored...),
+		policy:  g.Symlinks,
+		visited: make(map[string]bool),
+	}
+
+	if err := w.walk(dir, ""); err != nil {
+		return nil, err
+	}
+
+	return w.files, nil
+}
+
+type walker struct {
+	include []string
+	exclude []string
+	policy  string
+	visited map[string]bool
+	files   []walkedFile
+}
+
+func (w *walker) walk(dir, rel string) error {
+	// Track the real paths of the directories we're inside, so followed symlinks can't send us round in circles
+	if real, err := filepath.EvalSymlinks(dir); err == nil {
+		if w.visited[real] {
+			return nil
+		}
+		w.visited[real] = true
+		defer delete(w.visited, real)
+	}
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		p := filepath.Join(dir, entry.Name())
+		r := path.Join(rel, entry.Name())
+
+		if matchAny(w.exclude, r) {
+			continue
+		}
+
+		if entry.Mode()&os.ModeSymlink != 0 {
+			switch w.policy {
+			case SymlinksFollow:
+				if entry, err = os.Stat(p); err != nil {
+					return err
+				}
+			case SymlinksError:
+				return fmt.Errorf("Encountered symlink at %s", p)
+			default:
+				continue
+			}
+		}
+
+		if entry.IsDir() {
+			if err := w.walk(p, r); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !entry.Mode().IsRegular() {
+			continue
+		}
+
+		if len(w.include) > 0 && !matchAny(w.include, r) {
+			continue
+		}
+
+		w.files = append(w.files, walkedFile{Path: p, Rel: r})
+	}
+
+	return nil
+}
+
+// readIgnoreFile reads the exclude patterns of an IgnoreFile, one per line. Blank lines and lines starting with '#'
+// are ignored. A missing file has no patterns.
+func readIgnoreFile(name string) ([]string, error) {
+	f, err := os.Open(name)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	patterns := []string{}
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		patterns = append(patterns, line)
+	}
+
+	return patterns, s.Err()
+}
+
+func matchAny(patterns []string, rel string) bool {
+	for _, p := range patterns {
+		if matchGlob(p, rel) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchGlob reports whether the slash separated relative path matches the pattern. Patterns without a '/' are
+// matched against the base name of the path, so '*.js' matches JS files at any depth. Within a pattern, '**' matches
+// any number of path segments, so 'vendor/**' matches everything beneath vendor.
+func matchGlob(pattern, rel string) bool {
+	pattern = strings.TrimPrefix(pattern, "/")
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(rel))
+		return ok
+	}
+
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(rel, "/"))
+}
+
+func matchSegments(pattern, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(segments); i++ {
+				if matchSegments(pattern[1:], segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(segments) == 0 {
+			return false
+		}
+
+		if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+			return false
+		}
+
+		pattern, segments = pattern[1:], segments[1:]
+	}
+
+	return len(segments) == 0
+}
@@ -0,0 +1,117 @@
+package sri
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestMatchGlob(t *testing.T) {
+	type testCase struct {
+		pattern string
+		rel     string
+		exp     bool
+	}
+
+	testCases := []testCase{
+		{"*.js", "app.js", true},
+		{"*.js", "js/nested/app.js", true},
+		{"*.js", "app.css", false},
+		{"vendor/**", "vendor/jquery.js", true},
+		{"vendor/**", "vendor/a/b/jquery.js", true},
+		{"vendor/**", "src/vendor/jquery.js", false},
+		{"**/vendor/**", "src/vendor/jquery.js", true},
+		{"js/*.js", "js/app.js", true},
+		{"js/*.js", "js/nested/app.js", false},
+		{"js/**/*.js", "js/app.js", true},
+		{"/js/*.js", "js/app.js", true},
+	}
+
+	for _, tc := range testCases {
+		if match := matchGlob(tc.pattern, tc.rel); match != tc.exp {
+			t.Fatalf("Expected match of %s against %s to be %t", tc.pattern, tc.rel, tc.exp)
+		}
+	}
+}
+
+func TestDirRecursive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sri")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir. %q", err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"app.js":               "console.log('app');",
+		"app.css":              "body {}",
+		"README.md":            "# not an asset",
+		"js/nested/deep.js":    "console.log('deep');",
+		"vendor/jquery.js":     "console.log('jquery');",
+		"generated/ignored.js": "console.log('ignored');",
+		IgnoreFile:             "# generated files\n\ngenerated\n",
+	}
+
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(p), 0755)
+		if err := ioutil.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatalf("Unable to write %s. %q", p, err)
+		}
+	}
+
+	if err := os.Symlink(filepath.Join(dir, "js"), filepath.Join(dir, "linked")); err != nil {
+		t.Fatalf("Unable to create symlink. %q", err)
+	}
+
+	type testCase struct {
+		g   *Generator
+		exp []string
+		err bool
+	}
+
+	testCases := []testCase{
+		{
+			g:   &Generator{Hash: SHA256},
+			exp: []string{"README.md", "app.css", "app.js", "js/nested/deep.js", "vendor/jquery.js"},
+		},
+		{
+			g:   &Generator{Hash: SHA256, Include: []string{"*.js", "*.css"}, Exclude: []string{"vendor/**"}},
+			exp: []string{"app.css", "app.js", "js/nested/deep.js"},
+		},
+		{
+			g:   &Generator{Hash: SHA256, Include: []string{"*.js"}, Symlinks: SymlinksFollow},
+			exp: []string{"app.js", "js/nested/deep.js", "linked/nested/deep.js", "vendor/jquery.js"},
+		},
+		{
+			g:   &Generator{Hash: SHA256, Symlinks: SymlinksError},
+			err: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		fis, err := tc.g.Dir(dir)
+		if tc.err {
+			if err == nil {
+				t.Fatalf("Expected an error from Dir call with %+v", tc.g)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("Unexpected error from Dir call. %q", err)
+		}
+
+		names := []string{}
+		for _, fi := range fis {
+			names = append(names, fi.FileName)
+		}
+		sort.Strings(names)
+
+		if !reflect.DeepEqual(names, tc.exp) {
+			t.Fatalf("Expected files %q. Got %q", tc.exp, names)
+		}
+	}
+}
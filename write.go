@@ -1,47 +1,67 @@
-package main
+package sri
 
 import (
 	"encoding/json"
-	"fmt"
-	"os"
+	"io"
 	"strings"
 )
 
-func writeOutputToFile(fis []fileIntegrity, outPath string) error {
-	f, err := os.Create(outPath)
-	if err != nil {
-		return fmt.Errorf("Unable to create file at location: %s. %s", outPath, err)
-	}
-
-	j := json.NewEncoder(f)
-	j.SetEscapeHTML(false)
-	j.SetIndent("", "\t")
+// ManifestEntry is the digest and tag of a file for a single hashing algorithm, or for every algorithm when
+// generated with Combine.
+type ManifestEntry struct {
+	Digest    string    `json:"digest"`
+	Tag       string    `json:"tag"`
+	Source    string    `json:"source,omitempty"`
+	Path      string    `json:"path,omitempty"`
+	Integrity string    `json:"integrity,omitempty"`
+	Preload   string    `json:"preload,omitempty"`
+	Link      string    `json:"link,omitempty"`
+	Response  *Response `json:"response,omitempty"`
 
-	jsonFIs := constructJSONFileIntegrities(fis)
-	j.Encode(jsonFIs)
-
-	return nil
+	Environments map[string]Environment `json:"environments,omitempty"`
 }
 
-func constructJSONFileIntegrities(fis []fileIntegrity) map[string]interface{} {
-	result := make(map[string]interface{})
+// Manifest groups integrities by file name, and then by hashing algorithm. Combined integrities are keyed by each
+// of their algorithms separated by commas, e.g 'sha256,sha384'.
+type Manifest map[string]map[string]ManifestEntry
+
+// NewManifest constructs a Manifest from the given integrities.
+func NewManifest(fis []Integrity) Manifest {
+	result := make(Manifest)
 
 	for _, fi := range fis {
 		if result[fi.FileName] == nil {
-			result[fi.FileName] = make(map[string]interface{})
+			result[fi.FileName] = make(map[string]ManifestEntry)
 		}
 
-		fileNode := result[fi.FileName].(map[string]interface{})
-		algo := strings.Split(fi.Digest, "-")[0]
-		fileNode[algo] = make(map[string]interface{})
-		algoNode := fileNode[algo].(map[string]interface{})
+		algos := []string{}
+		for _, digest := range strings.Fields(fi.Digest) {
+			algos = append(algos, digestAlgorithm(digest))
+		}
 
-		algoNode["digest"] = fi.Digest
-		algoNode["tag"] = fi.Tag
-		if fi.Source != "" {
-			algoNode["source"] = fi.Source
+		algo := strings.Join(algos, ",")
+		result[fi.FileName][algo] = ManifestEntry{
+			Digest:    fi.Digest,
+			Tag:       fi.Tag,
+			Source:    fi.Source,
+			Path:      fi.Path,
+			Integrity: fi.Integrity,
+			Preload:   fi.Preload,
+			Link:      fi.Link,
+			Response:  fi.Response,
+
+			Environments: fi.Environments,
 		}
 	}
 
 	return result
 }
+
+// WriteManifest writes the Manifest of the given integrities to w as indented JSON.
+func WriteManifest(w io.Writer, fis []Integrity) error {
+	j := json.NewEncoder(w)
+	j.SetEscapeHTML(false)
+	j.SetIndent("", "\t")
+
+	return j.Encode(NewManifest(fis))
+}
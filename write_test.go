@@ -1,20 +1,29 @@
-package main
+package sri
 
 import (
 	"encoding/json"
 	"io/ioutil"
+	"os"
 	"strings"
 	"testing"
 )
 
-func TestWriteOutputToFile(t *testing.T) {
-	fis, err := generate([]string{"./test"}, allHashes)
+func TestWriteManifest(t *testing.T) {
+	g := &Generator{Hash: AllHashes}
+	fis, err := g.Generate([]string{"./test"})
 	if err != nil {
-		t.Fatalf("Unexpected error from generate call (target: './test'). %q", err)
+		t.Fatalf("Unexpected error from Generate call (target: './test'). %q", err)
 	}
 
-	if err := writeOutputToFile(fis, testWriteFileOutputPath); err != nil {
-		t.Fatalf("Unexpected error from writeOutputToFile call. %q", err)
+	f, err := os.Create(testWriteFileOutputPath)
+	if err != nil {
+		t.Fatalf("Unable to create %s. %q", testWriteFileOutputPath, err)
+	}
+
+	err = WriteManifest(f, fis)
+	f.Close()
+	if err != nil {
+		t.Fatalf("Unexpected error from WriteManifest call. %q", err)
 	}
 
 	assertFileContent(t, testWriteFileOutputPath, "test.js", "sha256", "sha256-jEUM4jWrIiMerWo9zYrx6XwQ5eI77uzuETBptBvPlRQ=")